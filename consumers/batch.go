@@ -0,0 +1,87 @@
+package consumers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/dns/v1"
+	"sort"
+)
+
+const (
+	// DefaultChangeBatchSize is the default maximum number of resource record sets (additions + deletions) in one change
+	DefaultChangeBatchSize = 100
+)
+
+var (
+	changeBatchesCounter *prometheus.CounterVec
+)
+
+func init() {
+	changeBatchesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_consumer",
+		Name:      "change_batches",
+		Help:      "Number of change batches submitted to the DNS service.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(changeBatchesCounter)
+}
+
+// batchDNSZoneChanges merges changes of the same DNS zone into changes with at most batchSize resource record sets.
+// Deletions are applied before modifications and modifications before additions, so a name released by a deletion
+// can be taken by an addition of the same batch or of a later one. A single change i.e. a modification of one DNS name
+// is never split, it exceeds batchSize only when it is larger than batchSize on its own.
+func batchDNSZoneChanges(dnsZoneChanges []*dnsZoneChange, batchSize int) []*dnsZoneChange {
+	if batchSize <= 0 {
+		batchSize = DefaultChangeBatchSize
+	}
+	zoneChanges := make(map[string][]*dnsZoneChange)
+	for _, v := range dnsZoneChanges {
+		if changeSize(v.change) > 0 {
+			zoneChanges[v.dnsZone] = append(zoneChanges[v.dnsZone], v)
+		}
+	}
+	dnsZones := make([]string, 0, len(zoneChanges))
+	for dnsZone := range zoneChanges {
+		dnsZones = append(dnsZones, dnsZone)
+	}
+	sort.Strings(dnsZones)
+
+	result := make([]*dnsZoneChange, 0, len(dnsZones))
+	for _, dnsZone := range dnsZones {
+		changes := zoneChanges[dnsZone]
+		sort.SliceStable(changes, func(i, j int) bool {
+			return changeOrder(changes[i].change) < changeOrder(changes[j].change)
+		})
+		var batch *dnsZoneChange
+		for _, v := range changes {
+			size := changeSize(v.change)
+			if batch == nil || changeSize(batch.change)+size > batchSize {
+				batch = &dnsZoneChange{dnsZone: dnsZone, change: new(dns.Change)}
+				result = append(result, batch)
+			}
+			batch.change.Deletions = append(batch.change.Deletions, v.change.Deletions...)
+			batch.change.Additions = append(batch.change.Additions, v.change.Additions...)
+		}
+	}
+	return result
+}
+
+// changeOrder orders deletions (0) before modifications (1) and additions (2)
+func changeOrder(change *dns.Change) int {
+	switch {
+	case len(change.Additions) == 0:
+		return 0
+	case len(change.Deletions) == 0:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func changeSize(change *dns.Change) int {
+	if change == nil {
+		return 0
+	}
+	return len(change.Additions) + len(change.Deletions)
+}
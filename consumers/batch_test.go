@@ -0,0 +1,95 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func rrs(name string) *dns.ResourceRecordSet {
+	return &dns.ResourceRecordSet{Name: name, Type: "A"}
+}
+
+func TestBatchDNSZoneChanges(t *testing.T) {
+	a := assert.New(t)
+
+	addition := func(zone string, name string) *dnsZoneChange {
+		return &dnsZoneChange{dnsZone: zone, change: &dns.Change{Additions: []*dns.ResourceRecordSet{rrs(name), rrs(name)}}}
+	}
+	deletion := func(zone string, name string) *dnsZoneChange {
+		return &dnsZoneChange{dnsZone: zone, change: &dns.Change{Deletions: []*dns.ResourceRecordSet{rrs(name), rrs(name)}}}
+	}
+	modification := func(zone string, name string) *dnsZoneChange {
+		return &dnsZoneChange{dnsZone: zone, change: &dns.Change{Deletions: []*dns.ResourceRecordSet{rrs(name), rrs(name)}, Additions: []*dns.ResourceRecordSet{rrs(name), rrs(name)}}}
+	}
+	names := func(rrsets []*dns.ResourceRecordSet) []string {
+		result := make([]string, 0, len(rrsets))
+		for i := 0; i < len(rrsets); i += 2 {
+			result = append(result, rrsets[i].Name)
+		}
+		return result
+	}
+
+	batches := batchDNSZoneChanges([]*dnsZoneChange{
+		addition("zone-b", "add-1"),
+		addition("zone-a", "add-1"),
+		modification("zone-a", "mod-1"),
+		deletion("zone-a", "del-1"),
+		addition("zone-a", "add-2"),
+		deletion("zone-a", "del-2"),
+		{dnsZone: "zone-a", change: &dns.Change{}},
+		{dnsZone: "zone-c"},
+	}, 8)
+
+	a.Len(batches, 3)
+	a.Equal("zone-a", batches[0].dnsZone)
+	a.Equal([]string{"del-1", "del-2", "mod-1"}, names(batches[0].change.Deletions))
+	a.Equal([]string{"mod-1"}, names(batches[0].change.Additions))
+
+	a.Equal("zone-a", batches[1].dnsZone)
+	a.Empty(batches[1].change.Deletions)
+	a.Equal([]string{"add-1", "add-2"}, names(batches[1].change.Additions))
+
+	a.Equal("zone-b", batches[2].dnsZone)
+	a.Equal([]string{"add-1"}, names(batches[2].change.Additions))
+
+	// a modification is not split, even when it is larger than batch size
+	batches = batchDNSZoneChanges([]*dnsZoneChange{modification("zone-a", "mod-1"), modification("zone-a", "mod-2")}, 1)
+	a.Len(batches, 2)
+	a.Len(batches[0].change.Deletions, 2)
+	a.Len(batches[0].change.Additions, 2)
+
+	a.Len(batchDNSZoneChanges(nil, 0), 0)
+}
+
+func TestSyncBulk(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": fi.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"}),
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		changeBatchSize:  4,
+		dnsService:       dnsService,
+	}
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "instance-2", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "europe-west1-c"},
+		{Hostname: "instance-3", DNSZone: "internal-example-com", IP: "10.132.0.3", ComputeZone: "europe-west1-c"},
+	}
+	a.NoError(gc.Sync([]string{"europe-west1-c"}, endpoints))
+
+	// deletion of instance-1 and addition of instance-2, then addition of instance-3
+	a.Len(dnsService.dnsZoneChanges, 2)
+	a.Len(dnsService.dnsZoneChanges[0].change.Deletions, 2)
+	a.Len(dnsService.dnsZoneChanges[0].change.Additions, 2)
+	a.Empty(dnsService.dnsZoneChanges[1].change.Deletions)
+	a.Len(dnsService.dnsZoneChanges[1].change.Additions, 2)
+}
@@ -94,6 +94,7 @@ type dnsZoneChange struct {
 type cloudDNSService struct {
 	project string
 	service *dns.Service
+	retry   *pkg.RetryPolicy
 }
 
 func newCloudDNSService(project string, client *http.Client) (*cloudDNSService, error) {
@@ -101,7 +102,7 @@ func newCloudDNSService(project string, client *http.Client) (*cloudDNSService,
 	if err != nil {
 		return nil, err
 	}
-	return &cloudDNSService{project: project, service: service}, nil
+	return &cloudDNSService{project: project, service: service, retry: pkg.NewGoogleRetryPolicy()}, nil
 }
 
 // GetProjectDNSZones provides list of all project DNS managed zones.
@@ -112,7 +113,11 @@ func (s *cloudDNSService) getProjectDNSZones() (map[string]string, error) {
 	}))
 	defer timer.ObserveDuration()
 
-	resp, err := s.service.ManagedZones.List(s.project).Do()
+	var resp *dns.ManagedZonesListResponse
+	err := s.retry.Do("dns_managed_zones_list", func() (err error) {
+		resp, err = s.service.ManagedZones.List(s.project).Do()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("[Cloud DNS] Error getting managed zones: %v", err)
 	}
@@ -138,7 +143,11 @@ func (s *cloudDNSService) getResourceRecordSets(dnsZone string) ([]*dns.Resource
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
-		resp, err := req.Do()
+		var resp *dns.ResourceRecordSetsListResponse
+		err := s.retry.Do("dns_resource_record_sets_list", func() (err error) {
+			resp, err = req.Do()
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("[Cloud DNS] Error getting DNS resourceRecordSets from zone %s: %v", dnsZone, err)
 		}
@@ -158,7 +167,12 @@ func (s *cloudDNSService) applyDNSZoneChange(dnsZoneChange *dnsZoneChange) error
 		log.Infof("Didn't submit change (no changes)")
 		return nil
 	}
-	_, err := s.service.Changes.Create(s.project, dnsZoneChange.dnsZone, dnsZoneChange.change).Do()
+	// a change failed with a precondition (412) has stale deletions and a change failed with a server error (5xx) may be
+	// committed, so only rate limited changes are resent and the next sync recomputes other failed changes
+	err := s.retry.DoChange("dns_changes_create", func() error {
+		_, err := s.service.Changes.Create(s.project, dnsZoneChange.dnsZone, dnsZoneChange.change).Do()
+		return err
+	})
 	if err != nil {
 		if strings.Contains(err.Error(), "alreadyExists") {
 			rrsChangeAlreadyExistedCounter.WithLabelValues(dnsZoneChange.dnsZone).Inc()
@@ -0,0 +1,52 @@
+package consumers
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"sort"
+	"strings"
+)
+
+// alias is a CNAME endpoint with its DNS name and the DNS name of the target
+type alias struct {
+	endpoint   *pkg.Endpoint
+	dnsName    string
+	targetName string
+}
+
+// sideTXTName returns the name of TXT record with labels of the CNAME, because CNAME names cannot have other records
+// e.g. _buddy.db-primary.internal.example.org.
+func sideTXTName(prefix string, dnsName string) string {
+	return "_" + strings.NewReplacer("/", "-", ".", "-").Replace(prefix) + "." + dnsName
+}
+
+// addAliasRecordGroups adds CNAME record groups of aliases. An alias is skipped when its DNS name has A or AAAA records,
+// the first target in alphabetical order is used when several targets claim the same alias.
+func (gc *GoogleConsumer) addAliasRecordGroups(recordGroups map[string]*RecordGroup, aliases []*alias) {
+	sort.Slice(aliases, func(i, j int) bool {
+		if aliases[i].dnsName != aliases[j].dnsName {
+			return aliases[i].dnsName < aliases[j].dnsName
+		}
+		return aliases[i].targetName < aliases[j].targetName
+	})
+	for _, v := range aliases {
+		recordGroup, exists := recordGroups[v.dnsName]
+		switch {
+		case !exists:
+			recordGroups[v.dnsName] = &RecordGroup{
+				DNSName: v.dnsName,
+				DNSZone: v.endpoint.DNSZone,
+				Target:  v.targetName,
+				TTL:     gc.endpointTTL(v.endpoint),
+				Labels:  []string{gc.newLabel(v.endpoint.Project, v.endpoint.ComputeZone, v.targetName)},
+				txtName: sideTXTName(gc.prefix(), v.dnsName),
+			}
+		case recordGroup.Target == v.targetName:
+			recordGroup.TTL = minTTL(recordGroup.TTL, gc.endpointTTL(v.endpoint))
+		case recordGroup.Target == "":
+			log.Warningf("[Cloud DNS] Skip alias %s of %s, the name has addresses %v", v.dnsName, v.targetName, recordGroup.addresses())
+		case recordGroup.Target != v.targetName:
+			log.Warningf("[Cloud DNS] Skip alias %s of %s, the alias points at %s", v.dnsName, v.targetName, recordGroup.Target)
+		}
+	}
+}
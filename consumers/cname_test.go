@@ -0,0 +1,71 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestCNAMERecords(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	cname := fi.aRecord("db-primary", "db-1.internal.example.com.")
+	cname.Type = "CNAME"
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": {
+				cname,
+				fi.txtRecord("_buddy.db-primary", quote("buddy/europe-west1-c/db-1.internal.example.com.")...),
+				fi.aRecord("db-1", "10.132.0.1"),
+				fi.txtRecord("db-1", quote("buddy/europe-west1-c/10.132.0.1")...),
+			},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		dnsService:       dnsService,
+	}
+
+	current, err := gc.currentRecordGroups()
+	a.NoError(err)
+	a.Len(gc.filterOwnRecordGroups(current, []string{"europe-west1-c"}), 2)
+
+	// failover to db-2
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "db-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		{Hostname: "db-2", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "europe-west1-c"},
+		{Hostname: "db-primary", DNSZone: "internal-example-com", Type: pkg.RecordTypeCNAME, Target: "db-2", ComputeZone: "europe-west1-c"},
+		// A records take precedence
+		{Hostname: "db-1", DNSZone: "internal-example-com", Type: pkg.RecordTypeCNAME, Target: "db-2", ComputeZone: "europe-west1-c"},
+	}
+	plan, err := gc.Plan([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(plan.Additions, 1)
+	a.Equal("db-2.internal.example.com.", plan.Additions[0].DNSName)
+	a.Empty(plan.Deletions)
+	// CNAME and its TXT record are changed together
+	a.Len(plan.Modifications, 2)
+	a.EqualValues(&PlannedChange{
+		DNSZone: "internal-example-com",
+		DNSName: "_buddy.db-primary.internal.example.com.",
+		Before:  []*dns.ResourceRecordSet{{Name: "_buddy.db-primary.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/db-1.internal.example.com."}}},
+		After:   []*dns.ResourceRecordSet{{Name: "_buddy.db-primary.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/db-2.internal.example.com."}}},
+	}, plan.Modifications[0])
+	a.EqualValues(&PlannedChange{
+		DNSZone: "internal-example-com",
+		DNSName: "db-primary.internal.example.com.",
+		Before:  []*dns.ResourceRecordSet{{Name: "db-primary.internal.example.com.", Type: "CNAME", Ttl: 300, Rrdatas: []string{"db-1.internal.example.com."}}},
+		After:   []*dns.ResourceRecordSet{{Name: "db-primary.internal.example.com.", Type: "CNAME", Ttl: 300, Rrdatas: []string{"db-2.internal.example.com."}}},
+	}, plan.Modifications[1])
+
+	// the alias is removed
+	plan, err = gc.Plan([]string{"europe-west1-c"}, endpoints[:1])
+	a.NoError(err)
+	a.Len(plan.Deletions, 2)
+	a.Equal("_buddy.db-primary.internal.example.com.", plan.Deletions[0].DNSName)
+}
@@ -3,6 +3,7 @@ package consumers
 import (
 	"fmt"
 	"github.com/everesio/buddy/pkg"
+	"strings"
 )
 
 // Consumer consumer provided endpoints
@@ -13,9 +14,14 @@ type Consumer interface {
 
 // New creates A new producer
 func New(name string) (Consumer, error) {
+	if strings.HasSuffix(name, DryRunSuffix) {
+		return NewDryRunConsumer(strings.TrimSuffix(name, DryRunSuffix))
+	}
 	switch name {
 	case "google":
 		return NewGoogleConsumer()
+	case "rfc2136":
+		return NewRFC2136Consumer()
 	}
 	return nil, fmt.Errorf("Unknown consumer '%s'", name)
 }
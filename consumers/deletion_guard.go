@@ -0,0 +1,82 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"sort"
+)
+
+var (
+	deletionGuardRefusalsCounter *prometheus.CounterVec
+)
+
+func init() {
+	deletionGuardRefusalsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_consumer",
+		Name:      "deletion_guard_refusals",
+		Help:      "Number of synchronizations refused, because planned deletions exceeded the limit.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(deletionGuardRefusalsCounter)
+}
+
+// deletionGuard refuses changes deleting too many owned records of a DNS zone e.g. when the producer returned
+// a partial list of endpoints. Limits are disabled when zero.
+type deletionGuard struct {
+	maxDeletions        int
+	maxDeletionsPercent float64
+	force               bool
+}
+
+// check returns an error and counts the refusal, when deletions of a DNS zone exceed the absolute or the percentage
+// limit of owned records. It is used before changes are applied.
+func (g *deletionGuard) check(ownRecordGroups []*RecordGroup, dnsZoneChanges []*dnsZoneChange) error {
+	dnsZone, err := g.refusal(ownRecordGroups, dnsZoneChanges)
+	if err != nil {
+		deletionGuardRefusalsCounter.WithLabelValues(dnsZone).Inc()
+	}
+	return err
+}
+
+// refusal returns the first DNS zone with deletions exceeding the limits and the error describing the refusal
+func (g *deletionGuard) refusal(ownRecordGroups []*RecordGroup, dnsZoneChanges []*dnsZoneChange) (string, error) {
+	if g == nil || (g.maxDeletions <= 0 && g.maxDeletionsPercent <= 0) {
+		return "", nil
+	}
+	owned := make(map[string]int)
+	for _, v := range ownRecordGroups {
+		owned[v.DNSZone]++
+	}
+	deletions := make(map[string]int)
+	for _, v := range dnsZoneChanges {
+		if len(v.change.Deletions) > 0 && len(v.change.Additions) == 0 {
+			deletions[v.dnsZone]++
+		}
+	}
+	dnsZones := make([]string, 0, len(deletions))
+	for dnsZone := range deletions {
+		dnsZones = append(dnsZones, dnsZone)
+	}
+	sort.Strings(dnsZones)
+
+	for _, dnsZone := range dnsZones {
+		count := deletions[dnsZone]
+		exceeded := g.maxDeletions > 0 && count > g.maxDeletions
+		if g.maxDeletionsPercent > 0 && float64(count)*100 > g.maxDeletionsPercent*float64(owned[dnsZone]) {
+			exceeded = true
+		}
+		if !exceeded {
+			continue
+		}
+		if g.force {
+			log.Warnf("[Cloud DNS] Forced deletion of %d of %d owned records in zone %s", count, owned[dnsZone], dnsZone)
+			continue
+		}
+		return dnsZone, fmt.Errorf("[Cloud DNS] Refused to delete %d of %d owned records in zone %s (max deletions %d, max deletions percent %.1f). Use --force-deletions to override",
+			count, owned[dnsZone], dnsZone, g.maxDeletions, g.maxDeletionsPercent)
+	}
+	return "", nil
+}
@@ -0,0 +1,80 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestDeletionGuard(t *testing.T) {
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	owned := []*RecordGroup{
+		fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1"),
+		fi.recordGroup("instance-2", "10.132.0.2", "buddy/europe-west1-c/10.132.0.2"),
+		fi.recordGroup("instance-3", "10.132.0.3", "buddy/europe-west1-c/10.132.0.3"),
+		fi.recordGroup("instance-4", "10.132.0.4", "buddy/europe-west1-c/10.132.0.4"),
+	}
+	deletion := &dnsZoneChange{dnsZone: "internal-example-com", change: &dns.Change{Deletions: toResourceRecordSet(owned[0])}}
+	modification := &dnsZoneChange{dnsZone: "internal-example-com", change: &dns.Change{Deletions: toResourceRecordSet(owned[1]), Additions: toResourceRecordSet(owned[1])}}
+	twoDeletions := []*dnsZoneChange{deletion, deletion, modification}
+
+	for _, tc := range []struct {
+		name    string
+		guard   *deletionGuard
+		changes []*dnsZoneChange
+		refused bool
+	}{
+		{name: "disabled", guard: &deletionGuard{}, changes: twoDeletions},
+		{name: "not configured", changes: twoDeletions},
+		{name: "below max deletions", guard: &deletionGuard{maxDeletions: 2}, changes: twoDeletions},
+		{name: "above max deletions", guard: &deletionGuard{maxDeletions: 1}, changes: twoDeletions, refused: true},
+		{name: "modifications are not deletions", guard: &deletionGuard{maxDeletions: 1}, changes: []*dnsZoneChange{deletion, modification, modification}},
+		{name: "below max deletions percent", guard: &deletionGuard{maxDeletionsPercent: 50}, changes: twoDeletions},
+		{name: "above max deletions percent", guard: &deletionGuard{maxDeletionsPercent: 25}, changes: twoDeletions, refused: true},
+		{name: "forced", guard: &deletionGuard{maxDeletions: 1, force: true}, changes: twoDeletions},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.guard.check(owned, tc.changes)
+			assert.Equal(t, tc.refused, err != nil, err)
+		})
+	}
+}
+
+func TestSyncRefusedByDeletionGuard(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": append(
+				fi.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"}),
+				fi.aAndTxtRecords("instance-2", []string{"10.132.0.2"}, []string{"buddy/europe-west1-c/10.132.0.2"})...),
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL:        300,
+		dnsZones:      map[string]struct{}{"internal-example-com": {}},
+		dnsService:    dnsService,
+		deletionGuard: &deletionGuard{maxDeletionsPercent: 50},
+	}
+
+	// empty list of endpoints
+	err := gc.Sync([]string{"europe-west1-c"}, []*pkg.Endpoint{})
+	a.Error(err)
+	a.Empty(dnsService.dnsZoneChanges)
+
+	// refused deletions are planned
+	plan, err := gc.Plan([]string{"europe-west1-c"}, []*pkg.Endpoint{})
+	a.NoError(err)
+	a.Len(plan.Deletions, 2)
+	a.Contains(plan.Refused, "Refused to delete 2 of 2 owned records in zone internal-example-com")
+	a.Contains(plan.String(), "Refused: ")
+
+	err = gc.Sync([]string{"europe-west1-c"}, []*pkg.Endpoint{
+		{Hostname: "instance-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+	})
+	a.NoError(err)
+	a.Len(dnsService.dnsZoneChanges, 1)
+}
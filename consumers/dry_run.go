@@ -0,0 +1,52 @@
+package consumers
+
+import (
+	"encoding/json"
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+)
+
+// DryRunSuffix appended to a consumer name selects its dry-run variant e.g. google-dry-run
+const DryRunSuffix = "-dry-run"
+
+// DryRunConsumer calculates changes against live state, but never applies them
+type DryRunConsumer struct {
+	Consumer
+	planner Planner
+}
+
+// NewDryRunConsumer creates a dry-run variant of the named consumer
+func NewDryRunConsumer(name string) (*DryRunConsumer, error) {
+	consumer, err := New(name)
+	if err != nil {
+		return nil, err
+	}
+	planner, ok := consumer.(Planner)
+	if !ok {
+		return nil, fmt.Errorf("Consumer '%s' does not support dry-run", name)
+	}
+	log.Infof("[Dry run] Changes calculated by consumer '%s' will not be applied", name)
+	return &DryRunConsumer{Consumer: consumer, planner: planner}, nil
+}
+
+// Sync logs planned changes instead of applying them
+func (d *DryRunConsumer) Sync(computeZones []string, endpoints []*pkg.Endpoint) error {
+	plan, err := d.Plan(computeZones, endpoints)
+	if err != nil {
+		return err
+	}
+	log.Infof("[Dry run] Planned changes:\n%s", plan)
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("[Dry run] Unable to marshal plan: %v", err)
+	}
+	log.Debugf("[Dry run] Plan: %s", data)
+	return nil
+}
+
+// Plan calculates changes using the wrapped consumer
+func (d *DryRunConsumer) Plan(computeZones []string, endpoints []*pkg.Endpoint) (*Plan, error) {
+	return d.planner.Plan(computeZones, endpoints)
+}
@@ -9,30 +9,21 @@ import (
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/dns/v1"
+	"net"
 	"reflect"
 	"sort"
 	"strings"
 )
 
 var (
-	// buddy/<compute-zone>
-	computeZonePrefix string
-	// buddy/<compute-zone>/IPv4
-	labelPrefix string
-
 	additionsCounter     *prometheus.CounterVec
 	deletionsCounter     *prometheus.CounterVec
 	modificationsCounter *prometheus.CounterVec
+	ttlDriftCounter      *prometheus.CounterVec
+	labelDriftCounter    *prometheus.CounterVec
 )
 
 func init() {
-	buddyPrefix := pkg.GoogleConfig.BuddyLabelPrefix
-	if buddyPrefix == "" {
-		buddyPrefix = pkg.DefaultBuddyLabelPrefix
-	}
-	computeZonePrefix = buddyPrefix + "/%s"
-	labelPrefix = computeZonePrefix + "/%s"
-
 	additionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "buddy",
 		Subsystem: "google_consumer",
@@ -62,36 +53,86 @@ func init() {
 		[]string{"dns_zone"},
 	)
 	prometheus.MustRegister(modificationsCounter)
+
+	ttlDriftCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_consumer",
+		Name:      "rrs_ttl_drift",
+		Help:      "Number of calculated resource record set modifications correcting TTL of records with unchanged IPs.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(ttlDriftCounter)
+
+	labelDriftCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_consumer",
+		Name:      "rrs_label_drift",
+		Help:      "Number of calculated resource record set modifications correcting TXT labels of records with unchanged IPs.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(labelDriftCounter)
 }
 
 // GoogleConsumer synchronizes google cloud DNS
 type GoogleConsumer struct {
-	dnsTTL           int64
+	dnsTTL int64
+	// bounds of endpoint TTL overrides, maximum is unlimited when zero
+	minTTL           int64
+	maxTTL           int64
 	dnsZones         map[string]struct{}
 	multipleIPRecord bool
+	changeBatchSize  int
 	dnsService       dnsService
+	// prefix of TXT labels, DefaultBuddyLabelPrefix when empty
+	labelPrefix string
+	// records are owned when labels contain the owner ID
+	ownerID string
+	// records with legacy labels without owner are owned also when ownerID is set
+	adoptLegacyLabels bool
+	// compute projects of endpoints, labels of other projects are not owned
+	projects map[string]struct{}
+	// project attribute is added to labels
+	projectLabels bool
+	deletionGuard *deletionGuard
+	// sync, upsert-only or create-only
+	policy string
+	// PTR records are managed in reverse zones among dnsZones
+	ptrRecords bool
 }
 
 // NewGoogleConsumer creates a new GoogleConsumer
 func NewGoogleConsumer() (*GoogleConsumer, error) {
-	if pkg.GoogleConfig.Project == "" {
-		return nil, errors.New("Please provide --google-project")
+	project := pkg.GoogleDNSProject()
+	if project == "" {
+		return nil, errors.New("Please provide --google-project or --google-dns-project")
 	}
 	client, err := google.DefaultClient(context.Background(), dns.NdevClouddnsReadwriteScope)
 	if err != nil {
 		return nil, fmt.Errorf("[Cloud DNS] Unable to create google oauth2 http client %v", err)
 	}
-	dnsService, err := newCloudDNSService(pkg.GoogleConfig.Project, client)
+	dnsService, err := newCloudDNSService(project, client)
 	if err != nil {
 		return nil, fmt.Errorf("[Cloud DNS] Unable to create cloud dns service: %v", err)
 	}
 
+	gc, err := newGoogleConsumer(dnsService)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[Cloud DNS] Google consumer: project %s, dns zones %v, policy %s", project, reflect.ValueOf(gc.dnsZones).MapKeys(), gc.policy)
+	return gc, nil
+}
+
+// newGoogleConsumer creates GoogleConsumer which manages configured zones of the dnsService
+func newGoogleConsumer(dnsService dnsService) (*GoogleConsumer, error) {
 	allDNSZones, err := dnsService.getProjectDNSZones()
 	if err != nil {
 		return nil, err
 	}
 
-	dnsZones := getZonesToManage()
+	dnsZones := pkg.GoogleDNSZones()
 	if len(dnsZones) == 0 {
 		return nil, errors.New("Please provide --dns-zones")
 	}
@@ -105,33 +146,52 @@ func NewGoogleConsumer() (*GoogleConsumer, error) {
 	if dnsTTL < 0 {
 		dnsTTL = 300
 	}
-
-	log.Printf("[Cloud DNS] Google consumer: project %s, dns zones %v", pkg.GoogleConfig.Project, reflect.ValueOf(dnsZones).MapKeys())
-	return &GoogleConsumer{dnsTTL: dnsTTL, dnsZones: dnsZones, multipleIPRecord: pkg.GoogleConfig.MultipleIPRecord, dnsService: dnsService}, nil
-}
-
-func getZonesToManage() map[string]struct{} {
-	result := make(map[string]struct{})
-
-	if pkg.GoogleConfig.ExternalIPDNSZone != "" {
-		result[pkg.GoogleConfig.ExternalIPDNSZone] = struct{}{}
+	if err = validateTTLBounds(pkg.GoogleConfig.DNSMinTTL, pkg.GoogleConfig.DNSMaxTTL); err != nil {
+		return nil, err
 	}
-	if pkg.GoogleConfig.InternalIPDNSZone != "" {
-		result[pkg.GoogleConfig.InternalIPDNSZone] = struct{}{}
+	changeBatchSize := pkg.GoogleConfig.ChangeBatchSize
+	if changeBatchSize <= 0 {
+		changeBatchSize = DefaultChangeBatchSize
 	}
-	for _, zone := range strings.Split(pkg.GoogleConfig.DNSZones, ",") {
-		if zone != "" {
-			result[zone] = struct{}{}
-		}
+	if err = validatePolicy(pkg.GoogleConfig.Policy); err != nil {
+		return nil, err
 	}
-	return result
+	if pkg.GoogleConfig.OwnerID != "" && strings.ContainsAny(pkg.GoogleConfig.OwnerID, "/=\"") {
+		return nil, fmt.Errorf("Owner ID '%s' must not contain '/', '=' or '\"'", pkg.GoogleConfig.OwnerID)
+	}
+	projects := make(map[string]struct{})
+	for _, project := range pkg.GoogleComputeProjects() {
+		projects[project] = struct{}{}
+	}
+	return &GoogleConsumer{
+		dnsTTL:            dnsTTL,
+		minTTL:            pkg.GoogleConfig.DNSMinTTL,
+		maxTTL:            pkg.GoogleConfig.DNSMaxTTL,
+		dnsZones:          dnsZones,
+		multipleIPRecord:  pkg.GoogleConfig.MultipleIPRecord,
+		changeBatchSize:   changeBatchSize,
+		dnsService:        dnsService,
+		labelPrefix:       pkg.GoogleConfig.BuddyLabelPrefix,
+		ownerID:           pkg.GoogleConfig.OwnerID,
+		adoptLegacyLabels: pkg.GoogleConfig.AdoptLegacyLabels,
+		projects:          projects,
+		projectLabels:     pkg.GoogleProjectLabels(),
+		deletionGuard: &deletionGuard{
+			maxDeletions:        pkg.GoogleConfig.MaxDeletions,
+			maxDeletionsPercent: pkg.GoogleConfig.MaxDeletionsPercent,
+			force:               pkg.GoogleConfig.ForceDeletions,
+		},
+		policy:     pkg.GoogleConfig.Policy,
+		ptrRecords: pkg.GoogleConfig.PTRRecords,
+	}, nil
 }
 
+// Sync synchronizes provided endpoints with Cloud DNS using batched changes
 func (gc *GoogleConsumer) Sync(computeZones []string, endpoints []*pkg.Endpoint) error {
-	return gc.SyncOne(computeZones, endpoints)
+	return gc.SyncBulk(computeZones, endpoints)
 }
 
-// Sync synchronizes provided endpoints with Cloud DNS
+// SyncOne synchronizes provided endpoints with Cloud DNS applying one change per DNS name
 func (gc *GoogleConsumer) SyncOne(computeZones []string, endpoints []*pkg.Endpoint) error {
 	dnsZoneChanges, err := gc.getDNSZoneChanges(computeZones, endpoints)
 	if err != nil {
@@ -146,28 +206,32 @@ func (gc *GoogleConsumer) SyncOne(computeZones []string, endpoints []*pkg.Endpoi
 	return nil
 }
 
-func (gc *GoogleConsumer) SyncBulk(computeZones []string, endpoints []*pkg.Endpoint) error {
+// Plan calculates changes without applying them. Changes refused by the deletion guard are planned and the refusal is reported in the plan.
+func (gc *GoogleConsumer) Plan(computeZones []string, endpoints []*pkg.Endpoint) (*Plan, error) {
+	ownRecordGroups, dnsZoneChanges, err := gc.planDNSZoneChanges(computeZones, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	plan := newPlan(dnsZoneChanges)
+	if _, err = gc.deletionGuard.refusal(ownRecordGroups, dnsZoneChanges); err != nil {
+		plan.Refused = err.Error()
+	}
+	return plan, nil
+}
 
+// SyncBulk synchronizes provided endpoints with Cloud DNS applying changes of a DNS zone in batches of at most changeBatchSize records
+func (gc *GoogleConsumer) SyncBulk(computeZones []string, endpoints []*pkg.Endpoint) error {
 	dnsZoneChanges, err := gc.getDNSZoneChanges(computeZones, endpoints)
 	if err != nil {
 		return err
 	}
-	zoneChanges := make(map[string]*dnsZoneChange)
-	for _, v := range dnsZoneChanges {
-		zoneChange, exists := zoneChanges[v.dnsZone]
-		if !exists {
-			zoneChange = new(dnsZoneChange)
-			zoneChanges[v.dnsZone] = zoneChange
-		}
-		zoneChange.change.Additions = append(zoneChange.change.Additions, v.change.Additions...)
-		zoneChange.change.Deletions = append(zoneChange.change.Deletions, v.change.Deletions...)
-	}
-
-	for dnsZone, change := range zoneChanges {
-		err = gc.dnsService.applyDNSZoneChange(change)
+	batches := batchDNSZoneChanges(dnsZoneChanges, gc.changeBatchSize)
+	for i, v := range batches {
+		err = gc.dnsService.applyDNSZoneChange(v)
 		if err != nil {
-			return fmt.Errorf("Error applying change for %s: %v", dnsZone, err)
+			return fmt.Errorf("Error applying change batch %d/%d for %s: %v", i+1, len(batches), v.dnsZone, err)
 		}
+		changeBatchesCounter.WithLabelValues(v.dnsZone).Inc()
 	}
 	return nil
 }
@@ -184,33 +248,59 @@ func (gc *GoogleConsumer) endpointsRecordGroups(computeZones []string, endpoints
 	}
 
 	recordGroups := map[string]*RecordGroup{}
+	aliases := make([]*alias, 0)
+	serviceRecords := make([]*serviceRecord, 0)
 	for _, endpoint := range endpoints {
-		if endpoint.Hostname == "" || endpoint.ComputeZone == "" || endpoint.DNSZone == "" || endpoint.IP == "" {
-			log.Warningf("[Cloud DNS] Skip invalid endpoint: %v", endpoint)
+		if err := endpoint.Validate(); err != nil {
+			log.Warningf("[Cloud DNS] Skip invalid endpoint %v: %v", endpoint, err)
 			continue
 		}
 		if _, computeZoneOk := computeZonesMap[endpoint.ComputeZone]; computeZoneOk {
 			if zoneDNSName, zoneDNSNameOk := managedZones[endpoint.DNSZone]; zoneDNSNameOk {
 				dnsName := strings.Trim(endpoint.Hostname, ".") + "." + strings.Trim(zoneDNSName, ".") + "."
+				if endpoint.RecordType() == pkg.RecordTypeCNAME {
+					// aliases are added when all addresses are known
+					targetName := strings.Trim(endpoint.Target, ".") + "." + strings.Trim(zoneDNSName, ".") + "."
+					aliases = append(aliases, &alias{endpoint: endpoint, dnsName: dnsName, targetName: targetName})
+					continue
+				}
+				if endpoint.RecordType() == pkg.RecordTypeSRV {
+					// services are added when all addresses and aliases are known
+					targetName := strings.Trim(endpoint.Target, ".") + "." + strings.Trim(zoneDNSName, ".") + "."
+					serviceRecords = append(serviceRecords, &serviceRecord{endpoint: endpoint, dnsName: dnsName, targetName: targetName})
+					continue
+				}
 
+				// TTL of the group is the lowest TTL of its endpoints
+				ttl := gc.endpointTTL(endpoint)
 				recordGroup, exists := recordGroups[dnsName]
 				if !exists {
 					recordGroup = &RecordGroup{
 						DNSName: dnsName,
 						DNSZone: endpoint.DNSZone,
-						IPs:     make([]string, 0, 1),
-						TTL:     gc.dnsTTL,
+						TTL:     ttl,
 						Labels:  []string{},
 					}
 				}
-				recordGroup.IPs = append(recordGroup.IPs, endpoint.IP)
-				recordGroup.Labels = append(recordGroup.Labels, fmt.Sprintf(labelPrefix, endpoint.ComputeZone, endpoint.IP))
+				recordGroup.TTL = minTTL(recordGroup.TTL, ttl)
+				// canonical form of the address is returned by the DNS service. Endpoints can share an IP
+				// e.g. forwarding rules of several ports, duplicate rrdatas are rejected by the DNS service.
+				ip := net.ParseIP(endpoint.IP).String()
+				if endpoint.RecordType() == pkg.RecordTypeAAAA {
+					recordGroup.IPv6s = appendUnique(recordGroup.IPv6s, ip)
+				} else {
+					recordGroup.IPs = appendUnique(recordGroup.IPs, ip)
+				}
+				recordGroup.Labels = appendUnique(recordGroup.Labels, gc.newLabel(endpoint.Project, endpoint.ComputeZone, ip))
 				recordGroups[dnsName] = recordGroup
 
 			}
 		}
 	}
 
+	gc.addAliasRecordGroups(recordGroups, aliases)
+	gc.addServiceRecordGroups(recordGroups, serviceRecords)
+
 	if !gc.multipleIPRecord {
 		recordGroups = removeMultipleIPRecord(recordGroups)
 	}
@@ -219,14 +309,17 @@ func (gc *GoogleConsumer) endpointsRecordGroups(computeZones []string, endpoints
 	for _, v := range recordGroups {
 		result = append(result, v)
 	}
+	if gc.ptrRecords {
+		result = append(result, gc.ptrRecordGroups(result, managedZones)...)
+	}
 	return result, nil
 }
 
 func removeMultipleIPRecord(recordGroups map[string]*RecordGroup) map[string]*RecordGroup {
 	result := map[string]*RecordGroup{}
 	for dnsName, recordGroup := range recordGroups {
-		if len(recordGroup.IPs) > 1 {
-			log.Warningf("[Cloud DNS] Skip multiple IP record for %s: %v", dnsName, recordGroup.IPs)
+		if len(recordGroup.IPs) > 1 || len(recordGroup.IPv6s) > 1 {
+			log.Warningf("[Cloud DNS] Skip multiple IP record for %s: %v", dnsName, recordGroup.addresses())
 			continue
 		}
 		result[dnsName] = recordGroup
@@ -234,20 +327,35 @@ func removeMultipleIPRecord(recordGroups map[string]*RecordGroup) map[string]*Re
 	return result
 }
 
+// getDNSZoneChanges returns changes to be applied, they are checked by the deletion guard
 func (gc *GoogleConsumer) getDNSZoneChanges(computeZones []string, endpoints []*pkg.Endpoint) ([]*dnsZoneChange, error) {
-	currentRecordGroups, err := gc.currentRecordGroups()
+	ownRecordGroups, dnsZoneChanges, err := gc.planDNSZoneChanges(computeZones, endpoints)
 	if err != nil {
 		return nil, err
 	}
-	ownRecordGroups := filterOwnRecordGroups(currentRecordGroups, computeZones)
+	if err = gc.deletionGuard.check(ownRecordGroups, dnsZoneChanges); err != nil {
+		return nil, err
+	}
+	return dnsZoneChanges, nil
+}
+
+// planDNSZoneChanges returns owned record groups and changes of the sync policy synchronizing them with endpoints
+func (gc *GoogleConsumer) planDNSZoneChanges(computeZones []string, endpoints []*pkg.Endpoint) ([]*RecordGroup, []*dnsZoneChange, error) {
+	currentRecordGroups, err := gc.currentRecordGroups()
+	if err != nil {
+		return nil, nil, err
+	}
+	ownRecordGroups := gc.filterOwnRecordGroups(currentRecordGroups, computeZones)
 	targetRecordGroups, err := gc.endpointsRecordGroups(computeZones, endpoints)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return calcDNSZoneChanges(ownRecordGroups, targetRecordGroups), nil
+	return ownRecordGroups, calcDNSZoneChanges(gc.policy, ownRecordGroups, targetRecordGroups), nil
 }
 
-func calcDNSZoneChanges(existingRecordGroups []*RecordGroup, targetRecordGroups []*RecordGroup) []*dnsZoneChange {
+// calcDNSZoneChanges returns changes synchronizing existing record groups with target record groups, changes not
+// allowed by the policy are skipped before they are counted
+func calcDNSZoneChanges(policy string, existingRecordGroups []*RecordGroup, targetRecordGroups []*RecordGroup) []*dnsZoneChange {
 
 	existingMap := make(map[string]*RecordGroup)
 	for _, v := range existingRecordGroups {
@@ -271,23 +379,49 @@ func calcDNSZoneChanges(existingRecordGroups []*RecordGroup, targetRecordGroups
 			rrs := toResourceRecordSet(existingRecordGroup)
 			change.Deletions = append(change.Deletions, rrs...)
 			dnsZoneChange := &dnsZoneChange{dnsZone: existingRecordGroup.DNSZone, change: change}
+			if !policyAllows(policy, dnsZoneChange) {
+				continue
+			}
 			dnsZoneChanges = append(dnsZoneChanges, dnsZoneChange)
 
 			deletionsCounter.WithLabelValues(existingRecordGroup.DNSZone).Inc()
-			log.Infof("[Cloud DNS]: Change deletion: %s / %v", existingRecordGroup.DNSName, existingRecordGroup.IPs)
+			log.Infof("[Cloud DNS]: Change deletion: %s / %v", existingRecordGroup.DNSName, existingRecordGroup.addresses())
 
 		} else {
-			existingIPs := sortedCopy(existingRecordGroup.IPs)
-			targetIPs := sortedCopy(targetRecordGroup.IPs)
-			if !stringArrayEquals(existingIPs, targetIPs) {
+			ipsChanged := !stringArrayEquals(sortedCopy(existingRecordGroup.IPs), sortedCopy(targetRecordGroup.IPs)) ||
+				!stringArrayEquals(sortedCopy(existingRecordGroup.IPv6s), sortedCopy(targetRecordGroup.IPv6s)) ||
+				!stringArrayEquals(sortedCopy(existingRecordGroup.PTRs), sortedCopy(targetRecordGroup.PTRs)) ||
+				!stringArrayEquals(sortedCopy(existingRecordGroup.SRVs), sortedCopy(targetRecordGroup.SRVs)) ||
+				existingRecordGroup.Target != targetRecordGroup.Target
+			ttlChanged := existingRecordGroup.TTL != targetRecordGroup.TTL ||
+				(existingRecordGroup.txtTTL != 0 && existingRecordGroup.txtTTL != targetRecordGroup.TTL) ||
+				(existingRecordGroup.aaaaTTL != 0 && existingRecordGroup.aaaaTTL != targetRecordGroup.TTL)
+			labelsChanged := !stringArrayEquals(sortedCopy(existingRecordGroup.Labels), sortedCopy(targetRecordGroup.Labels))
+			if ipsChanged || ttlChanged || labelsChanged {
 				change := new(dns.Change)
 				change.Deletions = append(change.Deletions, toResourceRecordSet(existingRecordGroup)...)
 				change.Additions = append(change.Additions, toResourceRecordSet(targetRecordGroup)...)
 				dnsZoneChange := &dnsZoneChange{dnsZone: existingRecordGroup.DNSZone, change: change}
+				if !policyAllows(policy, dnsZoneChange) {
+					continue
+				}
 				dnsZoneChanges = append(dnsZoneChanges, dnsZoneChange)
 
 				modificationsCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
-				log.Infof("[Cloud DNS]: Change modification: %s / %v -> %v", existingRecordGroup.DNSName, existingRecordGroup.IPs, targetRecordGroup.IPs)
+				switch {
+				case ipsChanged:
+					log.Infof("[Cloud DNS]: Change modification: %s / %v -> %v", existingRecordGroup.DNSName, existingRecordGroup.addresses(), targetRecordGroup.addresses())
+				case ttlChanged && labelsChanged:
+					ttlDriftCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
+					labelDriftCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
+					log.Infof("[Cloud DNS]: Change modification: %s / TTL %d -> %d, labels %v -> %v", existingRecordGroup.DNSName, existingRecordGroup.TTL, targetRecordGroup.TTL, existingRecordGroup.Labels, targetRecordGroup.Labels)
+				case ttlChanged:
+					ttlDriftCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
+					log.Infof("[Cloud DNS]: Change modification: %s / TTL %d -> %d", existingRecordGroup.DNSName, existingRecordGroup.TTL, targetRecordGroup.TTL)
+				default:
+					labelDriftCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
+					log.Infof("[Cloud DNS]: Change modification: %s / labels %v -> %v", existingRecordGroup.DNSName, existingRecordGroup.Labels, targetRecordGroup.Labels)
+				}
 			}
 		}
 	}
@@ -300,7 +434,7 @@ func calcDNSZoneChanges(existingRecordGroups []*RecordGroup, targetRecordGroups
 			dnsZoneChanges = append(dnsZoneChanges, dnsZoneChange)
 
 			additionsCounter.WithLabelValues(targetRecordGroup.DNSZone).Inc()
-			log.Infof("[Cloud DNS]: Change addition: %s / %v", targetRecordGroup.DNSName, targetRecordGroup.IPs)
+			log.Infof("[Cloud DNS]: Change addition: %s / %v", targetRecordGroup.DNSName, targetRecordGroup.addresses())
 		}
 	}
 	return dnsZoneChanges
@@ -314,29 +448,26 @@ func (gc *GoogleConsumer) Records(computeZones []string) (interface{}, error) {
 	}
 
 	recordGroups := make([]*RecordGroup, 0, 16)
-	for _, v := range filterOwnRecordGroups(currentRecordGroups, computeZones) {
+	for _, v := range gc.filterOwnRecordGroups(currentRecordGroups, computeZones) {
 		recordGroups = append(recordGroups, v)
 	}
 	return recordGroups, nil
 
 }
 
-func filterOwnRecordGroups(recordGroups []*RecordGroup, computeZones []string) []*RecordGroup {
-	computeZonesPrefixes := make(map[string]struct{})
+func (gc *GoogleConsumer) filterOwnRecordGroups(recordGroups []*RecordGroup, computeZones []string) []*RecordGroup {
+	computeZonesMap := make(map[string]struct{})
 	for _, computeZone := range computeZones {
-		computeZonesPrefixes[fmt.Sprintf(computeZonePrefix, computeZone)] = struct{}{}
+		computeZonesMap[computeZone] = struct{}{}
 	}
 	ownRecordGroups := make([]*RecordGroup, 0, len(recordGroups))
 	for _, record := range recordGroups {
 		skip := false
-	labelLoop:
 		for _, label := range record.Labels {
-			for computeZonesPrefix := range computeZonesPrefixes {
-				if strings.HasPrefix(label, computeZonesPrefix) {
-					continue labelLoop
-				}
+			if !gc.ownsLabel(label, computeZonesMap) {
+				skip = true
+				break
 			}
-			skip = true
 		}
 		if len(record.Labels) == 0 || skip {
 			log.Debugf("[Cloud DNS] Skip not owned record %v", record)
@@ -348,49 +479,177 @@ func filterOwnRecordGroups(recordGroups []*RecordGroup, computeZones []string) [
 	return ownRecordGroups
 }
 
+func (gc *GoogleConsumer) prefix() string {
+	if gc.labelPrefix == "" {
+		return pkg.DefaultBuddyLabelPrefix
+	}
+	return gc.labelPrefix
+}
+
+// newLabel creates TXT label of the IP address in the compute zone of the project. Project is optional and it is added
+// only when the consumer has project labels.
+func (gc *GoogleConsumer) newLabel(project string, computeZone string, ip string) string {
+	if !gc.projectLabels {
+		project = ""
+	}
+	l := &label{Prefix: gc.prefix(), ComputeZone: computeZone, IP: ip, Project: project, Owner: gc.ownerID}
+	return l.String()
+}
+
+// ownsLabel returns true when the label has the consumer prefix, one of compute zones, one of compute projects
+// and the consumer owner. Labels without project are owned by any project.
+// Legacy labels without owner are owned, when the consumer has no owner ID or adopts legacy labels.
+func (gc *GoogleConsumer) ownsLabel(value string, computeZones map[string]struct{}) bool {
+	l, err := parseLabel(value)
+	if err != nil || l.Prefix != gc.prefix() {
+		return false
+	}
+	if _, ok := computeZones[l.ComputeZone]; !ok {
+		return false
+	}
+	if _, ok := gc.projects[l.Project]; l.Project != "" && len(gc.projects) > 0 && !ok {
+		return false
+	}
+	if l.Owner == "" {
+		return gc.ownerID == "" || gc.adoptLegacyLabels
+	}
+	return l.Owner == gc.ownerID
+}
+
 func toResourceRecordSet(recordGroup *RecordGroup) []*dns.ResourceRecordSet {
-	return []*dns.ResourceRecordSet{
-		{
+	aaaaTTL := recordGroup.TTL
+	if recordGroup.aaaaTTL != 0 {
+		aaaaTTL = recordGroup.aaaaTTL
+	}
+	rrsets := make([]*dns.ResourceRecordSet, 0, 4)
+	if len(recordGroup.IPs) > 0 {
+		rrsets = append(rrsets, &dns.ResourceRecordSet{
 			Name:    recordGroup.DNSName,
 			Rrdatas: recordGroup.IPs,
 			Ttl:     recordGroup.TTL,
-			Type:    "A",
-		},
-		{
+			Type:    pkg.RecordTypeA,
+		})
+	}
+	if len(recordGroup.IPv6s) > 0 {
+		rrsets = append(rrsets, &dns.ResourceRecordSet{
 			Name:    recordGroup.DNSName,
-			Rrdatas: recordGroup.Labels,
+			Rrdatas: recordGroup.IPv6s,
+			Ttl:     aaaaTTL,
+			Type:    pkg.RecordTypeAAAA,
+		})
+	}
+	if recordGroup.Target != "" {
+		rrsets = append(rrsets, &dns.ResourceRecordSet{
+			Name:    recordGroup.DNSName,
+			Rrdatas: []string{recordGroup.Target},
 			Ttl:     recordGroup.TTL,
-			Type:    "TXT",
-		},
+			Type:    pkg.RecordTypeCNAME,
+		})
+	}
+	if len(recordGroup.PTRs) > 0 {
+		rrsets = append(rrsets, &dns.ResourceRecordSet{
+			Name:    recordGroup.DNSName,
+			Rrdatas: recordGroup.PTRs,
+			Ttl:     recordGroup.TTL,
+			Type:    recordTypePTR,
+		})
+	}
+	if len(recordGroup.SRVs) > 0 {
+		rrsets = append(rrsets, &dns.ResourceRecordSet{
+			Name:    recordGroup.DNSName,
+			Rrdatas: recordGroup.SRVs,
+			Ttl:     recordGroup.TTL,
+			Type:    pkg.RecordTypeSRV,
+		})
+	}
+	return append(rrsets, txtResourceRecordSet(recordGroup))
+}
+
+func txtResourceRecordSet(recordGroup *RecordGroup) *dns.ResourceRecordSet {
+	txtTTL := recordGroup.TTL
+	if recordGroup.txtTTL != 0 {
+		txtTTL = recordGroup.txtTTL
+	}
+	txtName := recordGroup.DNSName
+	if recordGroup.txtName != "" {
+		txtName = recordGroup.txtName
+	}
+	return &dns.ResourceRecordSet{
+		Name:    txtName,
+		Rrdatas: recordGroup.Labels,
+		Ttl:     txtTTL,
+		Type:    "TXT",
 	}
 }
 
 func (gc *GoogleConsumer) currentRecordGroups() ([]*RecordGroup, error) {
+	return gc.currentRecordGroupsWithPrefixes([]string{gc.prefix()})
+}
+
+// currentRecordGroupsWithPrefixes returns record groups of managed zones. TXT records of CNAMEs are named by one of the prefixes.
+func (gc *GoogleConsumer) currentRecordGroupsWithPrefixes(prefixes []string) ([]*RecordGroup, error) {
 	records := make(map[string]*RecordGroup)
+	txtTTLs := make(map[string]int64)
+	aaaaTTLs := make(map[string]int64)
 	for dnsZone := range gc.dnsZones {
 		resourceRecordSets, err := gc.dnsService.getResourceRecordSets(dnsZone)
 		if err != nil {
 			return nil, err
 		}
 		for _, r := range resourceRecordSets {
-			if r.Type == "A" || r.Type == "TXT" {
-				record, exists := records[r.Name]
+			if r.Type == pkg.RecordTypeA || r.Type == pkg.RecordTypeAAAA || r.Type == pkg.RecordTypeCNAME || r.Type == recordTypePTR || r.Type == pkg.RecordTypeSRV || r.Type == "TXT" {
+				name := r.Name
+				var txtName string
+				for _, prefix := range prefixes {
+					if r.Type == "TXT" && strings.HasPrefix(r.Name, sideTXTName(prefix, "")) {
+						name = strings.TrimPrefix(r.Name, sideTXTName(prefix, ""))
+						txtName = r.Name
+					}
+				}
+				record, exists := records[name]
 				if !exists {
-					record = &RecordGroup{DNSName: r.Name, DNSZone: dnsZone}
+					record = &RecordGroup{DNSName: name, DNSZone: dnsZone}
 				}
 				switch r.Type {
-				case "A":
+				case pkg.RecordTypeA:
 					record.IPs = r.Rrdatas
 					record.TTL = r.Ttl
+				case pkg.RecordTypeAAAA:
+					record.IPv6s = r.Rrdatas
+					aaaaTTLs[r.Name] = r.Ttl
+				case pkg.RecordTypeCNAME:
+					if len(r.Rrdatas) > 0 {
+						record.Target = r.Rrdatas[0]
+					}
+					record.TTL = r.Ttl
+				case recordTypePTR:
+					record.PTRs = r.Rrdatas
+					record.TTL = r.Ttl
+				case pkg.RecordTypeSRV:
+					record.SRVs = r.Rrdatas
+					record.TTL = r.Ttl
 				case "TXT":
 					record.Labels = trimLabels(r.Rrdatas)
+					record.txtName = txtName
+					txtTTLs[name] = r.Ttl
 				}
-				records[r.Name] = record
+				records[name] = record
 			}
 		}
 	}
 	result := make([]*RecordGroup, 0, len(records))
 	for _, v := range records {
+		// TTL of IPv6 only record is the AAAA record TTL
+		if aaaaTTL, ok := aaaaTTLs[v.DNSName]; ok && len(v.IPs) == 0 {
+			v.TTL = aaaaTTL
+		}
+		// AAAA and TXT records TTL are tracked only when they differ from A record TTL
+		if aaaaTTL, ok := aaaaTTLs[v.DNSName]; ok && aaaaTTL != v.TTL {
+			v.aaaaTTL = aaaaTTL
+		}
+		if txtTTL, ok := txtTTLs[v.DNSName]; ok && txtTTL != v.TTL {
+			v.txtTTL = txtTTL
+		}
 		result = append(result, v)
 	}
 	return result, nil
@@ -398,7 +657,7 @@ func (gc *GoogleConsumer) currentRecordGroups() ([]*RecordGroup, error) {
 
 func printRecordGroups(recordGroup []*RecordGroup) {
 	for _, v := range recordGroup {
-		log.Debugln(" ", v.DNSZone, v.DNSName, v.IPs, v.Labels, v.TTL)
+		log.Debugln(" ", v.DNSZone, v.DNSName, v.addresses(), v.Labels, v.TTL)
 	}
 }
 
@@ -436,11 +695,37 @@ func trimLabels(labels []string) []string {
 
 }
 
-// RecordGroup contains data from A and TXT record for the DNS name
+// RecordGroup contains data from A, AAAA, CNAME, PTR, SRV and TXT record for the DNS name
 type RecordGroup struct {
 	DNSName string   `json:"dnsName,omitempty"`
 	DNSZone string   `json:"dnsZone,omitempty"`
 	IPs     []string `json:"ips,omitempty"`
-	TTL     int64    `json:"ttl,omitempty"`
-	Labels  []string `json:"labels,omitempty"`
+	IPv6s   []string `json:"ipv6s,omitempty"`
+	// DNS names of the reverse DNS name
+	PTRs []string `json:"ptrs,omitempty"`
+	// SRV data e.g. 10 5 5432 db-1.internal.example.org.
+	SRVs []string `json:"srvs,omitempty"`
+	// CNAME target DNS name
+	Target string   `json:"target,omitempty"`
+	TTL    int64    `json:"ttl,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	// TTL of TXT record, when it differs from TTL of A record
+	txtTTL int64
+	// TTL of AAAA record, when it differs from TTL of A record
+	aaaaTTL int64
+	// name of TXT record, when it differs from DNS name e.g. TXT record of CNAME
+	txtName string
+}
+
+// addresses returns IPv4 and IPv6 addresses, PTR DNS names, SRV data or CNAME target of the group
+func (g *RecordGroup) addresses() []string {
+	addresses := make([]string, 0, len(g.IPs)+len(g.IPv6s)+len(g.PTRs)+len(g.SRVs)+1)
+	addresses = append(addresses, g.IPs...)
+	addresses = append(addresses, g.IPv6s...)
+	addresses = append(addresses, g.PTRs...)
+	addresses = append(addresses, g.SRVs...)
+	if g.Target != "" {
+		addresses = append(addresses, g.Target)
+	}
+	return addresses
 }
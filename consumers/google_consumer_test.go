@@ -167,6 +167,34 @@ func TestCurrentRecordGroups(t *testing.T) {
 
 }
 
+func TestCurrentRecordGroupsTXTTTL(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 400}
+	txt := fi.txtRecord("instance-1", quote("buddy/europe-west1-c/10.132.0.1")...)
+	txt.Ttl = 600
+
+	gc := &GoogleConsumer{
+		dnsZones: map[string]struct{}{
+			"internal-example-com": {},
+		},
+		dnsService: &fakeDNSService{
+			managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+				"internal-example-com": {
+					txt,
+					fi.aRecord("instance-1", "10.132.0.1"),
+				},
+			},
+		},
+	}
+	result, err := gc.currentRecordGroups()
+	a.NoError(err)
+
+	expected := fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1")
+	expected.txtTTL = 600
+	a.EqualValues([]*RecordGroup{expected}, result)
+}
+
 func TestCalcDNSZoneChanges(t *testing.T) {
 	a := assert.New(t)
 
@@ -180,6 +208,18 @@ func TestCalcDNSZoneChanges(t *testing.T) {
 	rg2 := fi.recordGroup("instance-2", "10.132.0.2", "buddy/europe-west1-c/10.132.0.2")
 	ch2 := fi.aAndTxtRecords("instance-2", []string{"10.132.0.2"}, []string{"buddy/europe-west1-c/10.132.0.2"})
 
+	fiTTL := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	rg1TTL := fiTTL.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1")
+	ch1TTL := fiTTL.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"})
+
+	rg1TXTTTL := fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1")
+	rg1TXTTTL.txtTTL = 600
+	ch1TXTTTL := fi.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"})
+	ch1TXTTTL[1].Ttl = 600
+
+	rg1Label := fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-d/10.132.0.1")
+	ch1Label := fi.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-d/10.132.0.1"})
+
 	testCases := []struct {
 		testName             string
 		existingRecordGroups []*RecordGroup
@@ -318,6 +358,51 @@ func TestCalcDNSZoneChanges(t *testing.T) {
 				},
 			},
 		},
+		{
+			"nothing to do / multiple labels are sorted",
+			[]*RecordGroup{fi.multiRecordGroup("instance-1", []string{"10.132.0.1", "10.132.0.2"}, []string{"buddy/europe-west1-c/10.132.0.2", "buddy/europe-west1-c/10.132.0.1"})},
+			[]*RecordGroup{fi.multiRecordGroup("instance-1", []string{"10.132.0.1", "10.132.0.2"}, []string{"buddy/europe-west1-c/10.132.0.1", "buddy/europe-west1-c/10.132.0.2"})},
+			[]*dnsZoneChange{},
+		},
+		{
+			"modify record TTL",
+			[]*RecordGroup{rg1},
+			[]*RecordGroup{rg1TTL},
+			[]*dnsZoneChange{
+				{dnsZone: "internal-example-com",
+					change: &dns.Change{
+						Deletions: ch1,
+						Additions: ch1TTL,
+					},
+				},
+			},
+		},
+		{
+			"modify TXT record TTL",
+			[]*RecordGroup{rg1TXTTTL},
+			[]*RecordGroup{rg1},
+			[]*dnsZoneChange{
+				{dnsZone: "internal-example-com",
+					change: &dns.Change{
+						Deletions: ch1TXTTTL,
+						Additions: ch1,
+					},
+				},
+			},
+		},
+		{
+			"modify record labels",
+			[]*RecordGroup{rg1Label},
+			[]*RecordGroup{rg1},
+			[]*dnsZoneChange{
+				{dnsZone: "internal-example-com",
+					change: &dns.Change{
+						Deletions: ch1Label,
+						Additions: ch1,
+					},
+				},
+			},
+		},
 		{
 			"add IP to record",
 			[]*RecordGroup{fi.multiRecordGroup("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"})},
@@ -347,7 +432,7 @@ func TestCalcDNSZoneChanges(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			result := calcDNSZoneChanges(tc.existingRecordGroups, tc.targetRecordGroups)
+			result := calcDNSZoneChanges(PolicySync, tc.existingRecordGroups, tc.targetRecordGroups)
 			if !a.EqualValues(tc.dnsZoneChange, result) {
 				t.Fail()
 			}
@@ -460,7 +545,7 @@ func TestFilterOwnRecordGroups(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			result := filterOwnRecordGroups(tc.recordGroups, tc.computeZones)
+			result := (&GoogleConsumer{}).filterOwnRecordGroups(tc.recordGroups, tc.computeZones)
 			if !a.EqualValues(tc.ownRecordGroups, result) {
 				t.Fail()
 			}
@@ -537,3 +622,107 @@ func TestGetDNSZoneChanges(t *testing.T) {
 	a.True(ips["10.132.0.1"])
 	a.True(ips["10.132.0.2"])
 }
+
+func TestDualStackRecordGroups(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	aaaa := fi.aRecord("instance-2", "2600:1900::2")
+	aaaa.Type = "AAAA"
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		dnsService: &fakeDNSService{
+			projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+			managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+				"internal-example-com": {
+					aaaa,
+					fi.txtRecord("instance-2", quote("buddy/europe-west1-c/2600:1900::2")...),
+				},
+			},
+		},
+	}
+
+	current, err := gc.currentRecordGroups()
+	a.NoError(err)
+	a.EqualValues([]*RecordGroup{{
+		DNSName: "instance-2.internal.example.com.",
+		DNSZone: "internal-example-com",
+		IPv6s:   []string{"2600:1900::2"},
+		TTL:     300,
+		Labels:  []string{"buddy/europe-west1-c/2600:1900::2"},
+	}}, current)
+
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "instance-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		{Hostname: "instance-1", DNSZone: "internal-example-com", IP: "2600:1900:0:0::1", ComputeZone: "europe-west1-c"},
+		{Hostname: "instance-2", DNSZone: "internal-example-com", IP: "2600:1900::2", ComputeZone: "europe-west1-c"},
+	}
+	changes, err := gc.getDNSZoneChanges([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(changes, 1)
+	a.Empty(changes[0].change.Deletions)
+	a.EqualValues([]*dns.ResourceRecordSet{
+		{Name: "instance-1.internal.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"10.132.0.1"}},
+		{Name: "instance-1.internal.example.com.", Type: "AAAA", Ttl: 300, Rrdatas: []string{"2600:1900::1"}},
+		{Name: "instance-1.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/10.132.0.1", "buddy/europe-west1-c/2600:1900::1"}},
+	}, changes[0].change.Additions)
+}
+
+func TestProjectLabels(t *testing.T) {
+	a := assert.New(t)
+
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		projects:         map[string]struct{}{"service-a": {}, "service-b": {}},
+		projectLabels:    true,
+		dnsService: &fakeDNSService{
+			projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		},
+	}
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "db", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c", Project: "service-a"},
+		{Hostname: "db", DNSZone: "internal-example-com", IP: "10.142.0.1", ComputeZone: "europe-west1-c", Project: "service-b"},
+	}
+	recordGroups, err := gc.endpointsRecordGroups([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(recordGroups, 1)
+	a.Equal([]string{"buddy/europe-west1-c/10.132.0.1/project=service-a", "buddy/europe-west1-c/10.142.0.1/project=service-b"}, recordGroups[0].Labels)
+	a.Len(gc.filterOwnRecordGroups(recordGroups, []string{"europe-west1-c"}), 1)
+
+	// a deployment scanning other projects in the same compute zone doesn't own the records
+	other := &GoogleConsumer{projects: map[string]struct{}{"service-c": {}}}
+	a.Empty(other.filterOwnRecordGroups(recordGroups, []string{"europe-west1-c"}))
+}
+
+func TestForwardingRulesSharingIP(t *testing.T) {
+	a := assert.New(t)
+
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"external-example-com": {}},
+		multipleIPRecord: false,
+		dnsService: &fakeDNSService{
+			projectDNSZones: map[string]string{"external-example-com": "external.example.com."},
+			managedZoneRRS:  map[string][]*dns.ResourceRecordSet{},
+		},
+	}
+	// forwarding rules of ports 80 and 443 with the same IP and hostname
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "web", DNSZone: "external-example-com", IP: "35.0.0.10", ComputeZone: "global"},
+		{Hostname: "web", DNSZone: "external-example-com", IP: "35.0.0.10", ComputeZone: "global"},
+		{Hostname: "web", DNSZone: "external-example-com", IP: "2600:1901::10", ComputeZone: "global"},
+		{Hostname: "web", DNSZone: "external-example-com", IP: "2600:1901:0::10", ComputeZone: "global"},
+	}
+	plan, err := gc.Plan([]string{"global"}, endpoints)
+	a.NoError(err)
+	a.Len(plan.Additions, 1)
+	a.Equal([]*dns.ResourceRecordSet{
+		{Name: "web.external.example.com.", Type: "A", Ttl: 300, Rrdatas: []string{"35.0.0.10"}},
+		{Name: "web.external.example.com.", Type: "AAAA", Ttl: 300, Rrdatas: []string{"2600:1901::10"}},
+		{Name: "web.external.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/global/35.0.0.10", "buddy/global/2600:1901::10"}},
+	}, plan.Additions[0].After)
+}
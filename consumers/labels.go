@@ -0,0 +1,61 @@
+package consumers
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	labelAttributeProject = "project"
+	labelAttributeOwner   = "owner"
+)
+
+// label is the value of TXT record identifying records created via buddy:
+// <prefix>/<compute-zone>/<ip>[/project=<project>][/owner=<owner-id>]
+// Legacy labels have no owner attribute. Project is the source project of the endpoint, when known.
+type label struct {
+	Prefix      string
+	ComputeZone string
+	IP          string
+	Project     string
+	Owner       string
+}
+
+// parseLabel parses the label value. The prefix can contain '/'.
+func parseLabel(value string) (*label, error) {
+	parts := strings.Split(value, "/")
+	l := &label{}
+	for len(parts) > 0 && strings.Contains(parts[len(parts)-1], "=") {
+		attribute := strings.SplitN(parts[len(parts)-1], "=", 2)
+		switch attribute[0] {
+		case labelAttributeProject:
+			l.Project = attribute[1]
+		case labelAttributeOwner:
+			l.Owner = attribute[1]
+		default:
+			return nil, fmt.Errorf("unknown attribute '%s' in label '%s'", attribute[0], value)
+		}
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid label '%s'", value)
+	}
+	l.Prefix = strings.Join(parts[:len(parts)-2], "/")
+	l.ComputeZone = parts[len(parts)-2]
+	l.IP = parts[len(parts)-1]
+	if l.Prefix == "" || l.ComputeZone == "" || l.IP == "" {
+		return nil, fmt.Errorf("invalid label '%s'", value)
+	}
+	return l, nil
+}
+
+func (l *label) String() string {
+	value := l.Prefix + "/" + l.ComputeZone + "/" + l.IP
+	if l.Project != "" {
+		value += "/" + labelAttributeProject + "=" + l.Project
+	}
+	if l.Owner != "" {
+		value += "/" + labelAttributeOwner + "=" + l.Owner
+	}
+	return value
+}
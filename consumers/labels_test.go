@@ -0,0 +1,69 @@
+package consumers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseLabel(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		label *label
+	}{
+		{value: "buddy/europe-west1-c/10.132.0.1", label: &label{Prefix: "buddy", ComputeZone: "europe-west1-c", IP: "10.132.0.1"}},
+		{value: "buddy/europe-west1-c/2600:1900::1/owner=prod", label: &label{Prefix: "buddy", ComputeZone: "europe-west1-c", IP: "2600:1900::1", Owner: "prod"}},
+		{value: "buddy/europe-west1-c/10.132.0.1/project=service-a/owner=prod", label: &label{Prefix: "buddy", ComputeZone: "europe-west1-c", IP: "10.132.0.1", Project: "service-a", Owner: "prod"}},
+		{value: "example.com/buddy/europe-west1-c/10.132.0.1/owner=prod", label: &label{Prefix: "example.com/buddy", ComputeZone: "europe-west1-c", IP: "10.132.0.1", Owner: "prod"}},
+		{value: "buddy/europe-west1-c/10.132.0.1/color=red"},
+		{value: "europe-west1-c/10.132.0.1"},
+		{value: "buddy//10.132.0.1"},
+		{value: "heritage=external-dns,external-dns/owner=default"},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			l, err := parseLabel(tc.value)
+			if tc.label == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.label, l)
+			assert.Equal(t, tc.value, l.String())
+		})
+	}
+}
+
+func TestOwnsLabel(t *testing.T) {
+	computeZones := map[string]struct{}{"europe-west1-c": {}}
+	for _, tc := range []struct {
+		name     string
+		consumer *GoogleConsumer
+		label    string
+		owns     bool
+	}{
+		{name: "legacy label without owner ID", consumer: &GoogleConsumer{}, label: "buddy/europe-west1-c/10.132.0.1", owns: true},
+		{name: "owned label without owner ID", consumer: &GoogleConsumer{}, label: "buddy/europe-west1-c/10.132.0.1/owner=prod"},
+		{name: "other compute zone", consumer: &GoogleConsumer{}, label: "buddy/europe-west1-d/10.132.0.1"},
+		{name: "other prefix", consumer: &GoogleConsumer{labelPrefix: "dns"}, label: "buddy/europe-west1-c/10.132.0.1"},
+		{name: "same owner", consumer: &GoogleConsumer{ownerID: "prod"}, label: "buddy/europe-west1-c/10.132.0.1/owner=prod", owns: true},
+		{name: "other owner", consumer: &GoogleConsumer{ownerID: "prod"}, label: "buddy/europe-west1-c/10.132.0.1/owner=staging"},
+		{name: "legacy label is adopted", consumer: &GoogleConsumer{ownerID: "prod", adoptLegacyLabels: true}, label: "buddy/europe-west1-c/10.132.0.1", owns: true},
+		{name: "legacy label is not adopted", consumer: &GoogleConsumer{ownerID: "prod"}, label: "buddy/europe-west1-c/10.132.0.1"},
+		{name: "compute project", consumer: &GoogleConsumer{projects: map[string]struct{}{"service-a": {}}}, label: "buddy/europe-west1-c/10.132.0.1/project=service-a", owns: true},
+		{name: "other compute project", consumer: &GoogleConsumer{projects: map[string]struct{}{"service-a": {}}}, label: "buddy/europe-west1-c/10.132.0.1/project=service-b"},
+		{name: "label without project", consumer: &GoogleConsumer{projects: map[string]struct{}{"service-a": {}}}, label: "buddy/europe-west1-c/10.132.0.1", owns: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.owns, tc.consumer.ownsLabel(tc.label, computeZones))
+		})
+	}
+}
+
+func TestNewLabel(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("buddy/europe-west1-c/10.132.0.1", (&GoogleConsumer{}).newLabel("", "europe-west1-c", "10.132.0.1"))
+	a.Equal("dns/europe-west1-c/10.132.0.1/owner=prod", (&GoogleConsumer{labelPrefix: "dns", ownerID: "prod"}).newLabel("", "europe-west1-c", "10.132.0.1"))
+	a.Equal("buddy/europe-west1-c/10.132.0.1/project=service-a/owner=prod", (&GoogleConsumer{ownerID: "prod", projectLabels: true}).newLabel("service-a", "europe-west1-c", "10.132.0.1"))
+	// single project labels are unchanged
+	a.Equal("buddy/europe-west1-c/10.132.0.1/owner=prod", (&GoogleConsumer{ownerID: "prod"}).newLabel("service-a", "europe-west1-c", "10.132.0.1"))
+}
@@ -0,0 +1,78 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"google.golang.org/api/dns/v1"
+)
+
+// LabelMigrator is implemented by consumers which can rewrite TXT labels of records created with another label prefix or owner ID
+type LabelMigrator interface {
+	// MigrateLabels calculates the TXT records changes and applies them, when apply is true
+	MigrateLabels(fromPrefix string, fromOwnerID string, apply bool) (*Plan, error)
+}
+
+// MigrateLabels rewrites TXT labels of records whose all labels have fromPrefix (the consumer label prefix when empty)
+// and fromOwnerID (legacy labels when empty) to the consumer label prefix and owner ID. A and AAAA records are not
+// changed. Changes are applied only when the deletion guard allows them.
+func (gc *GoogleConsumer) MigrateLabels(fromPrefix string, fromOwnerID string, apply bool) (*Plan, error) {
+	if fromPrefix == "" {
+		fromPrefix = gc.prefix()
+	}
+	currentRecordGroups, err := gc.currentRecordGroupsWithPrefixes([]string{fromPrefix, gc.prefix()})
+	if err != nil {
+		return nil, err
+	}
+	dnsZoneChanges := make([]*dnsZoneChange, 0)
+	for _, recordGroup := range currentRecordGroups {
+		labels, ok := gc.migratedLabels(recordGroup.Labels, fromPrefix, fromOwnerID)
+		if !ok || stringArrayEquals(labels, recordGroup.Labels) {
+			continue
+		}
+		migrated := *recordGroup
+		migrated.Labels = labels
+		if recordGroup.txtName != "" {
+			migrated.txtName = sideTXTName(gc.prefix(), recordGroup.DNSName)
+		}
+		change := &dns.Change{
+			Deletions: []*dns.ResourceRecordSet{txtResourceRecordSet(recordGroup)},
+			Additions: []*dns.ResourceRecordSet{txtResourceRecordSet(&migrated)},
+		}
+		dnsZoneChanges = append(dnsZoneChanges, &dnsZoneChange{dnsZone: recordGroup.DNSZone, change: change})
+		log.Debugf("[Migrate labels] %s / %v -> %v", recordGroup.DNSName, recordGroup.Labels, labels)
+	}
+	plan := newPlan(dnsZoneChanges)
+	if !apply {
+		if _, err = gc.deletionGuard.refusal(currentRecordGroups, dnsZoneChanges); err != nil {
+			plan.Refused = err.Error()
+		}
+		return plan, nil
+	}
+	if err = gc.deletionGuard.check(currentRecordGroups, dnsZoneChanges); err != nil {
+		return plan, err
+	}
+	batches := batchDNSZoneChanges(dnsZoneChanges, gc.changeBatchSize)
+	for i, v := range batches {
+		if err = gc.dnsService.applyDNSZoneChange(v); err != nil {
+			return plan, fmt.Errorf("[Migrate labels] Error applying change batch %d/%d for %s: %v", i+1, len(batches), v.dnsZone, err)
+		}
+	}
+	log.Infof("[Migrate labels] Migrated TXT records of %d DNS names", len(dnsZoneChanges))
+	return plan, nil
+}
+
+// migratedLabels returns new labels, when all labels have fromPrefix and fromOwnerID
+func (gc *GoogleConsumer) migratedLabels(labels []string, fromPrefix string, fromOwnerID string) ([]string, bool) {
+	if len(labels) == 0 {
+		return nil, false
+	}
+	result := make([]string, 0, len(labels))
+	for _, value := range labels {
+		l, err := parseLabel(value)
+		if err != nil || l.Prefix != fromPrefix || l.Owner != fromOwnerID {
+			return nil, false
+		}
+		result = append(result, gc.newLabel(l.Project, l.ComputeZone, l.IP))
+	}
+	return result, true
+}
@@ -0,0 +1,80 @@
+package consumers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestMigrateLabels(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": {
+				fi.aRecord("instance-1", "10.132.0.1", "10.132.0.2"),
+				fi.txtRecord("instance-1", quote("old/europe-west1-c/10.132.0.1", "old/europe-west1-d/10.132.0.2")...),
+				// other owner
+				fi.aRecord("instance-2", "10.132.0.3"),
+				fi.txtRecord("instance-2", quote("old/europe-west1-c/10.132.0.3/owner=staging")...),
+				// not created via buddy
+				fi.aRecord("instance-3", "10.132.0.4"),
+				// already migrated
+				fi.aRecord("instance-4", "10.132.0.5"),
+				fi.txtRecord("instance-4", quote("buddy/europe-west1-c/10.132.0.5/owner=prod")...),
+			},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsZones:   map[string]struct{}{"internal-example-com": {}},
+		dnsService: dnsService,
+		ownerID:    "prod",
+	}
+
+	plan, err := gc.MigrateLabels("old", "", false)
+	a.NoError(err)
+	a.Empty(dnsService.dnsZoneChanges)
+	a.Empty(plan.Additions)
+	a.Empty(plan.Deletions)
+	a.Len(plan.Modifications, 1)
+	a.Equal("instance-1.internal.example.com.", plan.Modifications[0].DNSName)
+
+	_, err = gc.MigrateLabels("old", "", true)
+	a.NoError(err)
+	a.Len(dnsService.dnsZoneChanges, 1)
+	change := dnsService.dnsZoneChanges[0].change
+	a.EqualValues([]*dns.ResourceRecordSet{
+		{Name: "instance-1.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"old/europe-west1-c/10.132.0.1", "old/europe-west1-d/10.132.0.2"}},
+	}, change.Deletions)
+	a.EqualValues([]*dns.ResourceRecordSet{
+		{Name: "instance-1.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/10.132.0.1/owner=prod", "buddy/europe-west1-d/10.132.0.2/owner=prod"}},
+	}, change.Additions)
+}
+
+func TestMigrateLabelsOfOwnPrefix(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": {
+				fi.aRecord("instance-1", "10.132.0.1"),
+				fi.txtRecord("instance-1", quote("buddy/europe-west1-c/10.132.0.1")...),
+			},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsZones:   map[string]struct{}{"internal-example-com": {}},
+		dnsService: dnsService,
+		ownerID:    "prod",
+	}
+
+	// legacy labels of the consumer prefix get the owner ID
+	plan, err := gc.MigrateLabels("", "", false)
+	a.NoError(err)
+	a.Len(plan.Modifications, 1)
+	a.Empty(plan.Refused)
+}
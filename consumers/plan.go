@@ -0,0 +1,122 @@
+package consumers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/everesio/buddy/pkg"
+	"google.golang.org/api/dns/v1"
+	"sort"
+	"strings"
+)
+
+// Planner is implemented by consumers which can calculate changes without applying them
+type Planner interface {
+	Plan(computeZones []string, endpoints []*pkg.Endpoint) (*Plan, error)
+}
+
+// Plan contains changes which would be applied by the consumer
+type Plan struct {
+	Additions     []*PlannedChange `json:"additions"`
+	Deletions     []*PlannedChange `json:"deletions"`
+	Modifications []*PlannedChange `json:"modifications"`
+	// reason of the deletion guard refusing the changes, empty when they would be applied
+	Refused string `json:"refused,omitempty"`
+}
+
+// PlannedChange contains resource record sets of the DNS name before and after the change
+type PlannedChange struct {
+	DNSZone string                   `json:"dnsZone"`
+	DNSName string                   `json:"dnsName"`
+	Before  []*dns.ResourceRecordSet `json:"before,omitempty"`
+	After   []*dns.ResourceRecordSet `json:"after,omitempty"`
+}
+
+func newPlan(dnsZoneChanges []*dnsZoneChange) *Plan {
+	plannedChanges := make(map[string]*PlannedChange)
+	for _, v := range dnsZoneChanges {
+		for _, rrs := range v.change.Deletions {
+			plannedChange := getPlannedChange(plannedChanges, v.dnsZone, rrs.Name)
+			plannedChange.Before = append(plannedChange.Before, rrs)
+		}
+		for _, rrs := range v.change.Additions {
+			plannedChange := getPlannedChange(plannedChanges, v.dnsZone, rrs.Name)
+			plannedChange.After = append(plannedChange.After, rrs)
+		}
+	}
+
+	plan := &Plan{
+		Additions:     []*PlannedChange{},
+		Deletions:     []*PlannedChange{},
+		Modifications: []*PlannedChange{},
+	}
+	for _, plannedChange := range plannedChanges {
+		switch {
+		case len(plannedChange.Before) == 0:
+			plan.Additions = append(plan.Additions, plannedChange)
+		case len(plannedChange.After) == 0:
+			plan.Deletions = append(plan.Deletions, plannedChange)
+		default:
+			plan.Modifications = append(plan.Modifications, plannedChange)
+		}
+	}
+	sortPlannedChanges(plan.Additions)
+	sortPlannedChanges(plan.Deletions)
+	sortPlannedChanges(plan.Modifications)
+	return plan
+}
+
+func getPlannedChange(plannedChanges map[string]*PlannedChange, dnsZone string, dnsName string) *PlannedChange {
+	key := dnsZone + "/" + dnsName
+	plannedChange, exists := plannedChanges[key]
+	if !exists {
+		plannedChange = &PlannedChange{DNSZone: dnsZone, DNSName: dnsName}
+		plannedChanges[key] = plannedChange
+	}
+	return plannedChange
+}
+
+func sortPlannedChanges(plannedChanges []*PlannedChange) {
+	sort.Slice(plannedChanges, func(i, j int) bool {
+		if plannedChanges[i].DNSZone != plannedChanges[j].DNSZone {
+			return plannedChanges[i].DNSZone < plannedChanges[j].DNSZone
+		}
+		return plannedChanges[i].DNSName < plannedChanges[j].DNSName
+	})
+}
+
+// Empty returns true when the plan does not contain any change
+func (p *Plan) Empty() bool {
+	return len(p.Additions) == 0 && len(p.Deletions) == 0 && len(p.Modifications) == 0
+}
+
+// String renders the plan as a human-readable diff
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+	var buf bytes.Buffer
+	for _, v := range p.Deletions {
+		fmt.Fprintf(&buf, "- %s (%s)\n", v.DNSName, v.DNSZone)
+		writeResourceRecordSets(&buf, "-", v.Before)
+	}
+	for _, v := range p.Modifications {
+		fmt.Fprintf(&buf, "~ %s (%s)\n", v.DNSName, v.DNSZone)
+		writeResourceRecordSets(&buf, "-", v.Before)
+		writeResourceRecordSets(&buf, "+", v.After)
+	}
+	for _, v := range p.Additions {
+		fmt.Fprintf(&buf, "+ %s (%s)\n", v.DNSName, v.DNSZone)
+		writeResourceRecordSets(&buf, "+", v.After)
+	}
+	fmt.Fprintf(&buf, "Plan: %d to add, %d to change, %d to delete.\n", len(p.Additions), len(p.Modifications), len(p.Deletions))
+	if p.Refused != "" {
+		fmt.Fprintf(&buf, "Refused: %s\n", p.Refused)
+	}
+	return buf.String()
+}
+
+func writeResourceRecordSets(buf *bytes.Buffer, sign string, resourceRecordSets []*dns.ResourceRecordSet) {
+	for _, rrs := range resourceRecordSets {
+		fmt.Fprintf(buf, "%s     %-5s %-6d %s\n", sign, rrs.Type, rrs.Ttl, strings.Join(rrs.Rrdatas, " "))
+	}
+}
@@ -0,0 +1,102 @@
+package consumers
+
+import (
+	"encoding/json"
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestNewPlan(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	rg1 := fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1")
+	rg2 := fi.recordGroup("instance-2", "10.132.0.2", "buddy/europe-west1-c/10.132.0.2")
+	rg2B := fi.recordGroup("instance-2", "10.132.0.20", "buddy/europe-west1-c/10.132.0.20")
+	rg3 := fi.recordGroup("instance-3", "10.132.0.3", "buddy/europe-west1-c/10.132.0.3")
+
+	plan := newPlan(calcDNSZoneChanges(PolicySync, []*RecordGroup{rg1, rg2}, []*RecordGroup{rg2B, rg3}))
+
+	a.Len(plan.Deletions, 1)
+	a.Len(plan.Modifications, 1)
+	a.Len(plan.Additions, 1)
+	a.False(plan.Empty())
+
+	a.Equal("instance-1.internal.example.com.", plan.Deletions[0].DNSName)
+	a.Equal(fi.aAndTxtRecords("instance-1", []string{"10.132.0.1"}, []string{"buddy/europe-west1-c/10.132.0.1"}), plan.Deletions[0].Before)
+	a.Empty(plan.Deletions[0].After)
+
+	a.Equal("instance-2.internal.example.com.", plan.Modifications[0].DNSName)
+	a.Equal(fi.aAndTxtRecords("instance-2", []string{"10.132.0.2"}, []string{"buddy/europe-west1-c/10.132.0.2"}), plan.Modifications[0].Before)
+	a.Equal(fi.aAndTxtRecords("instance-2", []string{"10.132.0.20"}, []string{"buddy/europe-west1-c/10.132.0.20"}), plan.Modifications[0].After)
+
+	a.Equal("instance-3.internal.example.com.", plan.Additions[0].DNSName)
+	a.Empty(plan.Additions[0].Before)
+
+	a.Equal(`- instance-1.internal.example.com. (internal-example-com)
+-     A     300    10.132.0.1
+-     TXT   300    buddy/europe-west1-c/10.132.0.1
+~ instance-2.internal.example.com. (internal-example-com)
+-     A     300    10.132.0.2
+-     TXT   300    buddy/europe-west1-c/10.132.0.2
++     A     300    10.132.0.20
++     TXT   300    buddy/europe-west1-c/10.132.0.20
++ instance-3.internal.example.com. (internal-example-com)
++     A     300    10.132.0.3
++     TXT   300    buddy/europe-west1-c/10.132.0.3
+Plan: 1 to add, 1 to change, 1 to delete.
+`, plan.String())
+
+	data, err := json.Marshal(plan)
+	a.NoError(err)
+	a.Contains(string(data), `"modifications":[{"dnsZone":"internal-example-com","dnsName":"instance-2.internal.example.com."`)
+}
+
+func TestEmptyPlan(t *testing.T) {
+	a := assert.New(t)
+
+	plan := newPlan([]*dnsZoneChange{})
+	a.True(plan.Empty())
+	a.Equal("No changes.\n", plan.String())
+}
+
+func TestDryRunConsumerSync(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.org.", dnsZone: "internal-example-com", ttl: 300}
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{
+			"internal-example-com": "internal.example.org.",
+		},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": {
+				fi.aRecord("instance-1", "10.132.0.1"),
+				fi.txtRecord("instance-1", quote("buddy/europe-west1-c/10.132.0.1")...),
+			},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL: 300,
+		dnsZones: map[string]struct{}{
+			"internal-example-com": {},
+		},
+		multipleIPRecord: true,
+		dnsService:       dnsService,
+	}
+	dryRun := &DryRunConsumer{Consumer: gc, planner: gc}
+
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "instance-2", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "europe-west1-c"},
+	}
+	err := dryRun.Sync([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Empty(dnsService.dnsZoneChanges)
+
+	plan, err := dryRun.Plan([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(plan.Additions, 1)
+	a.Len(plan.Deletions, 1)
+	a.Empty(plan.Modifications)
+}
@@ -0,0 +1,63 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// PolicySync applies additions, modifications and deletions
+	PolicySync = "sync"
+	// PolicyUpsertOnly applies additions and modifications
+	PolicyUpsertOnly = "upsert-only"
+	// PolicyCreateOnly applies additions
+	PolicyCreateOnly = "create-only"
+
+	changeKindDeletion     = "deletion"
+	changeKindModification = "modification"
+)
+
+var (
+	policySkippedChangesCounter *prometheus.CounterVec
+)
+
+func init() {
+	policySkippedChangesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_consumer",
+		Name:      "policy_skipped_changes",
+		Help:      "Number of calculated changes which were not applied due to the sync policy.",
+	},
+		[]string{"dns_zone", "policy", "kind"},
+	)
+	prometheus.MustRegister(policySkippedChangesCounter)
+}
+
+func validatePolicy(policy string) error {
+	switch policy {
+	case "", PolicySync, PolicyUpsertOnly, PolicyCreateOnly:
+		return nil
+	}
+	return fmt.Errorf("Unknown policy '%s'", policy)
+}
+
+// policyAllows returns false for changes not allowed by the policy: deletions for upsert-only, deletions and
+// modifications for create-only
+func policyAllows(policy string, dnsZoneChange *dnsZoneChange) bool {
+	if policy == "" || policy == PolicySync {
+		return true
+	}
+	var kind string
+	switch {
+	case len(dnsZoneChange.change.Deletions) > 0 && len(dnsZoneChange.change.Additions) == 0:
+		kind = changeKindDeletion
+	case len(dnsZoneChange.change.Deletions) > 0 && policy == PolicyCreateOnly:
+		kind = changeKindModification
+	default:
+		return true
+	}
+	policySkippedChangesCounter.WithLabelValues(dnsZoneChange.dnsZone, policy, kind).Inc()
+	log.Infof("[Cloud DNS]: Skip %s of %s due to %s policy", kind, dnsZoneChange.change.Deletions[0].Name, policy)
+	return false
+}
@@ -0,0 +1,36 @@
+package consumers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestPolicyAllows(t *testing.T) {
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	rg1 := fi.recordGroup("instance-1", "10.132.0.1", "buddy/europe-west1-c/10.132.0.1")
+	rg2 := fi.recordGroup("instance-2", "10.132.0.2", "buddy/europe-west1-c/10.132.0.2")
+	rg2B := fi.recordGroup("instance-2", "10.132.0.20", "buddy/europe-west1-c/10.132.0.20")
+	rg3 := fi.recordGroup("instance-3", "10.132.0.3", "buddy/europe-west1-c/10.132.0.3")
+
+	deletion := &dnsZoneChange{dnsZone: "internal-example-com", change: &dns.Change{Deletions: toResourceRecordSet(rg1)}}
+	modification := &dnsZoneChange{dnsZone: "internal-example-com", change: &dns.Change{Deletions: toResourceRecordSet(rg2), Additions: toResourceRecordSet(rg2B)}}
+	addition := &dnsZoneChange{dnsZone: "internal-example-com", change: &dns.Change{Additions: toResourceRecordSet(rg3)}}
+	changes := []*dnsZoneChange{deletion, modification, addition}
+
+	for _, tc := range []struct {
+		policy  string
+		changes []*dnsZoneChange
+	}{
+		{policy: "", changes: changes},
+		{policy: PolicySync, changes: changes},
+		{policy: PolicyUpsertOnly, changes: []*dnsZoneChange{modification, addition}},
+		{policy: PolicyCreateOnly, changes: []*dnsZoneChange{addition}},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			// skipped changes are not calculated
+			assert.Equal(t, tc.changes, calcDNSZoneChanges(tc.policy, []*RecordGroup{rg1, rg2}, []*RecordGroup{rg2B, rg3}))
+		})
+	}
+	assert.Error(t, validatePolicy("delete-only"))
+}
@@ -0,0 +1,97 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	recordTypePTR = "PTR"
+)
+
+// reverseName returns the reverse DNS name of the IP address e.g. 2.0.132.10.in-addr.arpa. for 10.132.0.2
+// or the ip6.arpa. nibble name of IPv6 address
+func reverseName(value string) (string, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return "", fmt.Errorf("invalid IP address '%s'", value)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip4[3], ip4[2], ip4[1], ip4[0]), nil
+	}
+	const hexDigits = "0123456789abcdef"
+	nibbles := make([]string, 0, 2*net.IPv6len+1)
+	for i := net.IPv6len - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[ip[i]&0x0f]), string(hexDigits[ip[i]>>4]))
+	}
+	return strings.Join(append(nibbles, "ip6.arpa."), "."), nil
+}
+
+// reverseDNSZone returns the managed zone with the longest DNS name which contains the reverse name
+func (gc *GoogleConsumer) reverseDNSZone(name string, managedZones map[string]string) (string, bool) {
+	var result string
+	var longest int
+	for dnsZone := range gc.dnsZones {
+		zoneDNSName := strings.Trim(managedZones[dnsZone], ".") + "."
+		if zoneDNSName == "." || !strings.HasSuffix(zoneDNSName, "arpa.") {
+			continue
+		}
+		if (name == zoneDNSName || strings.HasSuffix(name, "."+zoneDNSName)) && len(zoneDNSName) > longest {
+			result = dnsZone
+			longest = len(zoneDNSName)
+		}
+	}
+	return result, longest > 0
+}
+
+// ptrRecordGroups creates PTR record groups pointing at DNS names of the record groups. PTR records are created for
+// IP addresses with a reverse zone among managed zones and they have the same TXT labels and TTL as the forward records.
+func (gc *GoogleConsumer) ptrRecordGroups(recordGroups []*RecordGroup, managedZones map[string]string) []*RecordGroup {
+	ptrRecordGroups := make(map[string]*RecordGroup)
+	for _, recordGroup := range recordGroups {
+		if recordGroup.Target != "" || len(recordGroup.SRVs) > 0 {
+			continue
+		}
+		for _, value := range recordGroup.Labels {
+			l, err := parseLabel(value)
+			if err != nil {
+				continue
+			}
+			name, err := reverseName(l.IP)
+			if err != nil {
+				log.Warnf("[Cloud DNS] Skip PTR record of %s: %v", recordGroup.DNSName, err)
+				continue
+			}
+			dnsZone, ok := gc.reverseDNSZone(name, managedZones)
+			if !ok {
+				continue
+			}
+			ptrRecordGroup, exists := ptrRecordGroups[name]
+			if !exists {
+				ptrRecordGroup = &RecordGroup{DNSName: name, DNSZone: dnsZone, TTL: recordGroup.TTL, Labels: []string{}}
+				ptrRecordGroups[name] = ptrRecordGroup
+			}
+			ptrRecordGroup.TTL = minTTL(ptrRecordGroup.TTL, recordGroup.TTL)
+			ptrRecordGroup.PTRs = appendUnique(ptrRecordGroup.PTRs, recordGroup.DNSName)
+			ptrRecordGroup.Labels = appendUnique(ptrRecordGroup.Labels, value)
+		}
+	}
+	result := make([]*RecordGroup, 0, len(ptrRecordGroups))
+	for _, v := range ptrRecordGroups {
+		sort.Strings(v.PTRs)
+		result = append(result, v)
+	}
+	return result
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,71 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestReverseName(t *testing.T) {
+	for _, tc := range []struct {
+		ip   string
+		name string
+	}{
+		{ip: "10.132.0.2", name: "2.0.132.10.in-addr.arpa."},
+		{ip: "2600:1900::1", name: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.9.1.0.0.6.2.ip6.arpa."},
+		{ip: "invalid"},
+	} {
+		t.Run(tc.ip, func(t *testing.T) {
+			name, err := reverseName(tc.ip)
+			assert.Equal(t, tc.name, name)
+			assert.Equal(t, tc.name == "", err != nil)
+		})
+	}
+}
+
+func TestPTRRecords(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	fr := &fakeRecord{dnsName: "132.10.in-addr.arpa.", dnsZone: "reverse-10-132", ttl: 300}
+	ptr := fr.aRecord("3.0", "instance-3.internal.example.com.")
+	ptr.Type = "PTR"
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{
+			"internal-example-com": "internal.example.com.",
+			"reverse-10":           "10.in-addr.arpa.",
+			"reverse-10-132":       "132.10.in-addr.arpa.",
+		},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": fi.aAndTxtRecords("instance-3", []string{"10.132.0.3"}, quote("buddy/europe-west1-c/10.132.0.3")),
+			"reverse-10-132":       {ptr, fr.txtRecord("3.0", quote("buddy/europe-west1-c/10.132.0.3")...)},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}, "reverse-10": {}, "reverse-10-132": {}},
+		multipleIPRecord: true,
+		dnsService:       dnsService,
+		ptrRecords:       true,
+	}
+
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "instance-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		{Hostname: "db", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		// no reverse zone
+		{Hostname: "instance-2", DNSZone: "internal-example-com", IP: "192.168.0.2", ComputeZone: "europe-west1-c"},
+	}
+	plan, err := gc.Plan([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(plan.Additions, 4)
+	a.Equal("1.0.132.10.in-addr.arpa.", plan.Additions[3].DNSName)
+	a.Equal("reverse-10-132", plan.Additions[3].DNSZone)
+	a.EqualValues([]*dns.ResourceRecordSet{
+		{Name: "1.0.132.10.in-addr.arpa.", Type: "PTR", Ttl: 300, Rrdatas: []string{"db.internal.example.com.", "instance-1.internal.example.com."}},
+		{Name: "1.0.132.10.in-addr.arpa.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/10.132.0.1"}},
+	}, plan.Additions[3].After)
+	a.Len(plan.Deletions, 2)
+	a.Equal("3.0.132.10.in-addr.arpa.", plan.Deletions[1].DNSName)
+	a.Empty(plan.Modifications)
+}
@@ -0,0 +1,242 @@
+package consumers
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	mdns "github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/dns/v1"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	tsigFudge = 300
+)
+
+var (
+	tsigAlgorithms = map[string]string{
+		"hmac-md5":    mdns.HmacMD5,
+		"hmac-sha1":   mdns.HmacSHA1,
+		"hmac-sha256": mdns.HmacSHA256,
+		"hmac-sha512": mdns.HmacSHA512,
+	}
+
+	rfc2136TransferTimeSummary *prometheus.SummaryVec
+	rfc2136AdditionsCounter    *prometheus.CounterVec
+	rfc2136DeletionsCounter    *prometheus.CounterVec
+	rfc2136UpdateErrorCounter  *prometheus.CounterVec
+)
+
+func init() {
+	rfc2136TransferTimeSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace: "buddy",
+		Subsystem: "rfc2136",
+		Name:      "transfer_time",
+		Help:      "Time in milliseconds spent on zone transfers.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(rfc2136TransferTimeSummary)
+
+	rfc2136AdditionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "rfc2136",
+		Name:      "rrs_additions",
+		Help:      "Number of resource record set additions.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(rfc2136AdditionsCounter)
+
+	rfc2136DeletionsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "rfc2136",
+		Name:      "rrs_deletions",
+		Help:      "Number of resource record set deletions.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(rfc2136DeletionsCounter)
+
+	rfc2136UpdateErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "rfc2136",
+		Name:      "update_errors",
+		Help:      "Number of failed dynamic updates.",
+	},
+		[]string{"dns_zone"},
+	)
+	prometheus.MustRegister(rfc2136UpdateErrorCounter)
+}
+
+// RFC2136Consumer synchronizes DNS zones of BIND/Knot servers using zone transfers and dynamic updates.
+// Records are managed the same way as by GoogleConsumer.
+type RFC2136Consumer struct {
+	*GoogleConsumer
+}
+
+// NewRFC2136Consumer creates a new RFC2136Consumer
+func NewRFC2136Consumer() (*RFC2136Consumer, error) {
+	if pkg.RFC2136Config.Server == "" {
+		return nil, errors.New("Please provide --rfc2136-server")
+	}
+	dnsService, err := newRFC2136Service(pkg.RFC2136Config.Server, pkg.GoogleDNSZones(), pkg.RFC2136Config.TSIGKeyName, pkg.RFC2136Config.TSIGSecret, pkg.RFC2136Config.TSIGAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	gc, err := newGoogleConsumer(dnsService)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[RFC2136] RFC2136 consumer: server %s, dns zones %v, policy %s", pkg.RFC2136Config.Server, reflect.ValueOf(gc.dnsZones).MapKeys(), gc.policy)
+	return &RFC2136Consumer{GoogleConsumer: gc}, nil
+}
+
+// rfc2136Service reads zones via AXFR and applies changes with (TSIG signed) UPDATE messages.
+// DNS zone names are the zone FQDNs.
+type rfc2136Service struct {
+	server        string
+	zones         map[string]string
+	tsigKeyName   string
+	tsigSecret    string
+	tsigAlgorithm string
+}
+
+func newRFC2136Service(server string, dnsZones map[string]struct{}, tsigKeyName string, tsigSecret string, tsigAlgorithm string) (*rfc2136Service, error) {
+	zones := make(map[string]string)
+	for dnsZone := range dnsZones {
+		zones[dnsZone] = mdns.Fqdn(dnsZone)
+	}
+	s := &rfc2136Service{server: server, zones: zones}
+	if tsigKeyName != "" {
+		algorithm, ok := tsigAlgorithms[tsigAlgorithm]
+		if !ok {
+			return nil, fmt.Errorf("[RFC2136] Unsupported TSIG algorithm '%s'", tsigAlgorithm)
+		}
+		if tsigSecret == "" {
+			return nil, errors.New("Please provide --rfc2136-tsig-secret")
+		}
+		s.tsigKeyName = mdns.Fqdn(tsigKeyName)
+		s.tsigSecret = tsigSecret
+		s.tsigAlgorithm = algorithm
+	}
+	return s, nil
+}
+
+func (s *rfc2136Service) tsig(m *mdns.Msg) map[string]string {
+	if s.tsigKeyName == "" {
+		return nil
+	}
+	m.SetTsig(s.tsigKeyName, s.tsigAlgorithm, tsigFudge, time.Now().Unix())
+	return map[string]string{s.tsigKeyName: s.tsigSecret}
+}
+
+// getProjectDNSZones provides configured zones. It returns mapping DNSZone to its DNSName
+func (s *rfc2136Service) getProjectDNSZones() (map[string]string, error) {
+	return s.zones, nil
+}
+
+// getResourceRecordSets retrieves all resource records of the zone using AXFR
+func (s *rfc2136Service) getResourceRecordSets(dnsZone string) ([]*dns.ResourceRecordSet, error) {
+	timer := pkg.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		rfc2136TransferTimeSummary.WithLabelValues(dnsZone).Observe(v)
+	}))
+	defer timer.ObserveDuration()
+
+	zone, ok := s.zones[dnsZone]
+	if !ok {
+		return nil, fmt.Errorf("[RFC2136] Unknown DNS zone %s", dnsZone)
+	}
+	m := new(mdns.Msg)
+	m.SetAxfr(zone)
+	t := &mdns.Transfer{TsigSecret: s.tsig(m)}
+	envelopes, err := t.In(m, s.server)
+	if err != nil {
+		return nil, fmt.Errorf("[RFC2136] Error transferring zone %s: %v", zone, err)
+	}
+
+	resourceRecordSets := make([]*dns.ResourceRecordSet, 0, 16)
+	rrsByKey := make(map[string]*dns.ResourceRecordSet)
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("[RFC2136] Error transferring zone %s: %v", zone, envelope.Error)
+		}
+		for _, rr := range envelope.RR {
+			header := rr.Header()
+			rrType := mdns.TypeToString[header.Rrtype]
+			key := header.Name + " " + rrType
+			rrs, exists := rrsByKey[key]
+			if !exists {
+				rrs = &dns.ResourceRecordSet{Name: header.Name, Type: rrType, Ttl: int64(header.Ttl)}
+				rrsByKey[key] = rrs
+				resourceRecordSets = append(resourceRecordSets, rrs)
+			}
+			rrdata := strings.TrimPrefix(rr.String(), header.String())
+			// SOA is repeated at the end of the transfer
+			if rrType == "SOA" && len(rrs.Rrdatas) > 0 {
+				continue
+			}
+			rrs.Rrdatas = append(rrs.Rrdatas, rrdata)
+		}
+	}
+	return resourceRecordSets, nil
+}
+
+// applyDNSZoneChange sends the change as a single UPDATE message
+func (s *rfc2136Service) applyDNSZoneChange(dnsZoneChange *dnsZoneChange) error {
+	if len(dnsZoneChange.change.Additions) == 0 && len(dnsZoneChange.change.Deletions) == 0 {
+		log.Infof("[RFC2136] Didn't submit change (no changes)")
+		return nil
+	}
+	zone, ok := s.zones[dnsZoneChange.dnsZone]
+	if !ok {
+		return fmt.Errorf("[RFC2136] Unknown DNS zone %s", dnsZoneChange.dnsZone)
+	}
+
+	m := new(mdns.Msg)
+	m.SetUpdate(zone)
+	deletions, err := toRRs(dnsZoneChange.change.Deletions)
+	if err != nil {
+		return err
+	}
+	m.Remove(deletions)
+	additions, err := toRRs(dnsZoneChange.change.Additions)
+	if err != nil {
+		return err
+	}
+	m.Insert(additions)
+
+	c := &mdns.Client{Net: "tcp", TsigSecret: s.tsig(m)}
+	r, _, err := c.Exchange(m, s.server)
+	if err == nil && r.Rcode != mdns.RcodeSuccess {
+		err = fmt.Errorf("server responded %s", mdns.RcodeToString[r.Rcode])
+	}
+	if err != nil {
+		rfc2136UpdateErrorCounter.WithLabelValues(dnsZoneChange.dnsZone).Inc()
+		return fmt.Errorf("[RFC2136] Unable to update %s/%s: %v", s.server, zone, err)
+	}
+	rfc2136AdditionsCounter.WithLabelValues(dnsZoneChange.dnsZone).Add(float64(len(dnsZoneChange.change.Additions)))
+	rfc2136DeletionsCounter.WithLabelValues(dnsZoneChange.dnsZone).Add(float64(len(dnsZoneChange.change.Deletions)))
+	return nil
+}
+
+func toRRs(resourceRecordSets []*dns.ResourceRecordSet) ([]mdns.RR, error) {
+	rrs := make([]mdns.RR, 0, len(resourceRecordSets))
+	for _, rrset := range resourceRecordSets {
+		for _, rrdata := range rrset.Rrdatas {
+			if rrset.Type == "TXT" && !strings.HasPrefix(rrdata, `"`) {
+				rrdata = `"` + rrdata + `"`
+			}
+			rr, err := mdns.NewRR(fmt.Sprintf("%s %d IN %s %s", rrset.Name, rrset.Ttl, rrset.Type, rrdata))
+			if err != nil {
+				return nil, fmt.Errorf("[RFC2136] Invalid %s record %s: %v", rrset.Type, rrset.Name, err)
+			}
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs, nil
+}
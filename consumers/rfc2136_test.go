@@ -0,0 +1,195 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	mdns "github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testTSIGKeyName = "buddy."
+	testTSIGSecret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+)
+
+// fakeDNSServer is an in-process DNS server supporting TSIG signed AXFR and UPDATE of a single zone
+type fakeDNSServer struct {
+	sync.Mutex
+	zone string
+	rrs  []mdns.RR
+}
+
+func newFakeDNSServer(zone string, records ...string) *fakeDNSServer {
+	f := &fakeDNSServer{zone: zone}
+	records = append([]string{
+		zone + " 3600 IN SOA ns1." + zone + " hostmaster." + zone + " 1 3600 600 86400 300",
+		zone + " 3600 IN NS ns1." + zone,
+	}, records...)
+	for _, record := range records {
+		rr, err := mdns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+		f.rrs = append(f.rrs, rr)
+	}
+	return f
+}
+
+func (f *fakeDNSServer) start(t *testing.T) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &mdns.Server{
+		Listener:          l,
+		Handler:           f,
+		TsigSecret:        map[string]string{testTSIGKeyName: testTSIGSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// the default accepts only queries and notifies
+		MsgAcceptFunc: func(dh mdns.Header) mdns.MsgAcceptAction { return mdns.MsgAccept },
+	}
+	go server.ActivateAndServe()
+	<-started
+	return l.Addr().String(), func() { server.Shutdown() }
+}
+
+func (f *fakeDNSServer) ServeDNS(w mdns.ResponseWriter, r *mdns.Msg) {
+	f.Lock()
+	defer f.Unlock()
+
+	m := new(mdns.Msg)
+	m.SetReply(r)
+	switch {
+	case r.IsTsig() == nil || w.TsigStatus() != nil:
+		m.Rcode = mdns.RcodeRefused
+	case r.Opcode == mdns.OpcodeUpdate:
+		for _, rr := range r.Ns {
+			if rr.Header().Class == mdns.ClassNONE {
+				f.remove(rr)
+			} else {
+				f.rrs = append(f.rrs, rr)
+			}
+		}
+	case len(r.Question) == 1 && r.Question[0].Qtype == mdns.TypeAXFR:
+		m.Answer = append(m.Answer, f.rrs...)
+		m.Answer = append(m.Answer, f.rrs[0])
+	default:
+		m.Rcode = mdns.RcodeNotImplemented
+	}
+	if r.IsTsig() != nil {
+		m.SetTsig(testTSIGKeyName, mdns.HmacSHA256, tsigFudge, time.Now().Unix())
+	}
+	w.WriteMsg(m)
+}
+
+func (f *fakeDNSServer) remove(deleted mdns.RR) {
+	rrs := make([]mdns.RR, 0, len(f.rrs))
+	for _, rr := range f.rrs {
+		if rr.Header().Name == deleted.Header().Name && rr.Header().Rrtype == deleted.Header().Rrtype && rdata(rr) == rdata(deleted) {
+			continue
+		}
+		rrs = append(rrs, rr)
+	}
+	f.rrs = rrs
+}
+
+func (f *fakeDNSServer) records() []string {
+	f.Lock()
+	defer f.Unlock()
+
+	result := make([]string, 0, len(f.rrs))
+	for _, rr := range f.rrs {
+		if rr.Header().Rrtype == mdns.TypeA || rr.Header().Rrtype == mdns.TypeTXT {
+			result = append(result, rr.Header().Name+" "+mdns.TypeToString[rr.Header().Rrtype]+" "+rdata(rr))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func rdata(rr mdns.RR) string {
+	return strings.TrimPrefix(rr.String(), rr.Header().String())
+}
+
+func newTestRFC2136Consumer(t *testing.T, server string, tsigKeyName string) *RFC2136Consumer {
+	dnsZones := map[string]struct{}{"internal.example.org": {}}
+	dnsService, err := newRFC2136Service(server, dnsZones, tsigKeyName, testTSIGSecret, "hmac-sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &RFC2136Consumer{GoogleConsumer: &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         dnsZones,
+		multipleIPRecord: true,
+		dnsService:       dnsService,
+	}}
+}
+
+func TestRFC2136ConsumerSync(t *testing.T) {
+	a := assert.New(t)
+
+	fakeServer := newFakeDNSServer("internal.example.org.",
+		"instance-1.internal.example.org. 300 IN A 10.132.0.1",
+		`instance-1.internal.example.org. 300 IN TXT "buddy/europe-west1-c/10.132.0.1"`,
+		"mail.internal.example.org. 300 IN A 10.132.0.100",
+	)
+	server, shutdown := fakeServer.start(t)
+	defer shutdown()
+
+	consumer := newTestRFC2136Consumer(t, server, testTSIGKeyName)
+
+	records, err := consumer.Records([]string{"europe-west1-c"})
+	a.NoError(err)
+	a.Equal([]*RecordGroup{{
+		DNSName: "instance-1.internal.example.org.",
+		DNSZone: "internal.example.org",
+		IPs:     []string{"10.132.0.1"},
+		TTL:     300,
+		Labels:  []string{"buddy/europe-west1-c/10.132.0.1"},
+	}}, records)
+
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "instance-2", DNSZone: "internal.example.org", IP: "10.132.0.2", ComputeZone: "europe-west1-c"},
+		{Hostname: "instance-2", DNSZone: "internal.example.org", IP: "10.132.0.3", ComputeZone: "europe-west1-c"},
+	}
+	err = consumer.Sync([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Equal([]string{
+		"instance-2.internal.example.org. A 10.132.0.2",
+		"instance-2.internal.example.org. A 10.132.0.3",
+		`instance-2.internal.example.org. TXT "buddy/europe-west1-c/10.132.0.2"`,
+		`instance-2.internal.example.org. TXT "buddy/europe-west1-c/10.132.0.3"`,
+		"mail.internal.example.org. A 10.132.0.100",
+	}, fakeServer.records())
+
+	// nothing to do
+	plan, err := consumer.Plan([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.True(plan.Empty())
+}
+
+func TestRFC2136ConsumerUnsigned(t *testing.T) {
+	a := assert.New(t)
+
+	fakeServer := newFakeDNSServer("internal.example.org.")
+	server, shutdown := fakeServer.start(t)
+	defer shutdown()
+
+	consumer := newTestRFC2136Consumer(t, server, "")
+
+	_, err := consumer.Records([]string{"europe-west1-c"})
+	a.Error(err)
+}
+
+func TestNewRFC2136ServiceInvalidAlgorithm(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := newRFC2136Service("127.0.0.1:53", map[string]struct{}{}, testTSIGKeyName, testTSIGSecret, "hmac-sha3")
+	a.Error(err)
+}
@@ -0,0 +1,50 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"sort"
+)
+
+// serviceRecord is a SRV endpoint with its DNS name and the DNS name of the target
+type serviceRecord struct {
+	endpoint   *pkg.Endpoint
+	dnsName    string
+	targetName string
+}
+
+// srvData returns SRV record data e.g. 10 5 5432 db-1.internal.example.org.
+func (s *serviceRecord) srvData() string {
+	return fmt.Sprintf("%d %d %d %s", s.endpoint.Priority, s.endpoint.Weight, s.endpoint.Port, s.targetName)
+}
+
+// addServiceRecordGroups aggregates service records by DNS name into SRV record groups with the lowest TTL of the
+// instances, each instance has a TXT label of its target. A service record is skipped when its DNS name has A, AAAA
+// or CNAME records.
+func (gc *GoogleConsumer) addServiceRecordGroups(recordGroups map[string]*RecordGroup, serviceRecords []*serviceRecord) {
+	for _, v := range serviceRecords {
+		recordGroup, exists := recordGroups[v.dnsName]
+		if !exists {
+			recordGroup = &RecordGroup{
+				DNSName: v.dnsName,
+				DNSZone: v.endpoint.DNSZone,
+				TTL:     gc.endpointTTL(v.endpoint),
+				Labels:  []string{},
+			}
+			recordGroups[v.dnsName] = recordGroup
+		} else if len(recordGroup.SRVs) == 0 {
+			log.Warningf("[Cloud DNS] Skip service %s of %s, the name has records %v", v.dnsName, v.targetName, recordGroup.addresses())
+			continue
+		} else {
+			recordGroup.TTL = minTTL(recordGroup.TTL, gc.endpointTTL(v.endpoint))
+		}
+		recordGroup.SRVs = appendUnique(recordGroup.SRVs, v.srvData())
+		recordGroup.Labels = appendUnique(recordGroup.Labels, gc.newLabel(v.endpoint.Project, v.endpoint.ComputeZone, v.targetName))
+	}
+	for _, recordGroup := range recordGroups {
+		if len(recordGroup.SRVs) > 0 {
+			sort.Strings(recordGroup.SRVs)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestSRVRecords(t *testing.T) {
+	a := assert.New(t)
+
+	fi := &fakeRecord{dnsName: "internal.example.com.", dnsZone: "internal-example-com", ttl: 300}
+	srv := fi.aRecord("_postgres._tcp", "10 5 5432 db-1.internal.example.com.")
+	srv.Type = "SRV"
+	dnsService := &fakeDNSService{
+		projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+		managedZoneRRS: map[string][]*dns.ResourceRecordSet{
+			"internal-example-com": {
+				srv,
+				fi.txtRecord("_postgres._tcp", quote("buddy/europe-west1-c/db-1.internal.example.com.")...),
+				fi.aRecord("db-1", "10.132.0.1"),
+				fi.txtRecord("db-1", quote("buddy/europe-west1-c/10.132.0.1")...),
+			},
+		},
+	}
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		dnsService:       dnsService,
+	}
+
+	current, err := gc.currentRecordGroups()
+	a.NoError(err)
+	a.Len(gc.filterOwnRecordGroups(current, []string{"europe-west1-c"}), 2)
+
+	// db-2 joins the service
+	endpoints := []*pkg.Endpoint{
+		{Hostname: "db-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		{Hostname: "db-2", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "europe-west1-c"},
+		{Hostname: "_postgres._tcp", DNSZone: "internal-example-com", Type: pkg.RecordTypeSRV, Target: "db-2", Port: 5432, Priority: 10, Weight: 5, ComputeZone: "europe-west1-c"},
+		{Hostname: "_postgres._tcp", DNSZone: "internal-example-com", Type: pkg.RecordTypeSRV, Target: "db-1", Port: 5432, Priority: 10, Weight: 5, ComputeZone: "europe-west1-c"},
+		// A records take precedence
+		{Hostname: "db-1", DNSZone: "internal-example-com", Type: pkg.RecordTypeSRV, Target: "db-2", Port: 5432, ComputeZone: "europe-west1-c"},
+	}
+	plan, err := gc.Plan([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+	a.Len(plan.Additions, 1)
+	a.Equal("db-2.internal.example.com.", plan.Additions[0].DNSName)
+	a.Empty(plan.Deletions)
+	a.Len(plan.Modifications, 1)
+	a.EqualValues(&PlannedChange{
+		DNSZone: "internal-example-com",
+		DNSName: "_postgres._tcp.internal.example.com.",
+		Before: []*dns.ResourceRecordSet{
+			{Name: "_postgres._tcp.internal.example.com.", Type: "SRV", Ttl: 300, Rrdatas: []string{"10 5 5432 db-1.internal.example.com."}},
+			{Name: "_postgres._tcp.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/db-1.internal.example.com."}},
+		},
+		After: []*dns.ResourceRecordSet{
+			{Name: "_postgres._tcp.internal.example.com.", Type: "SRV", Ttl: 300, Rrdatas: []string{"10 5 5432 db-1.internal.example.com.", "10 5 5432 db-2.internal.example.com."}},
+			{Name: "_postgres._tcp.internal.example.com.", Type: "TXT", Ttl: 300, Rrdatas: []string{"buddy/europe-west1-c/db-2.internal.example.com.", "buddy/europe-west1-c/db-1.internal.example.com."}},
+		},
+	}, plan.Modifications[0])
+
+	// the service is removed
+	plan, err = gc.Plan([]string{"europe-west1-c"}, endpoints[:1])
+	a.NoError(err)
+	a.Len(plan.Deletions, 1)
+	a.Equal("_postgres._tcp.internal.example.com.", plan.Deletions[0].DNSName)
+}
@@ -1,6 +1,7 @@
 package consumers
 
 import (
+	"errors"
 	"github.com/everesio/buddy/pkg"
 	"sync"
 )
@@ -27,3 +28,14 @@ func (s *SyncedConsumer) Sync(computeZones []string, endpoints []*pkg.Endpoint)
 func (s *SyncedConsumer) Records(computeZones []string) (interface{}, error) {
 	return s.Consumer.Records(computeZones)
 }
+
+// Plan returns changes of the wrapped consumer, it doesn't run concurrently with Sync
+func (s *SyncedConsumer) Plan(computeZones []string, endpoints []*pkg.Endpoint) (*Plan, error) {
+	planner, ok := s.Consumer.(Planner)
+	if !ok {
+		return nil, errors.New("Consumer does not support planning")
+	}
+	s.Lock()
+	defer s.Unlock()
+	return planner.Plan(computeZones, endpoints)
+}
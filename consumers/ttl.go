@@ -0,0 +1,41 @@
+package consumers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+)
+
+// endpointTTL returns TTL override of the endpoint limited by the TTL bounds or the default TTL, when the endpoint
+// has no TTL. The maximum TTL is unlimited when zero.
+func (gc *GoogleConsumer) endpointTTL(endpoint *pkg.Endpoint) int64 {
+	if endpoint.TTL == 0 {
+		return gc.dnsTTL
+	}
+	switch {
+	case endpoint.TTL < gc.minTTL:
+		log.Warnf("[Cloud DNS] TTL %d of %s is below the minimum TTL %d", endpoint.TTL, endpoint.Hostname, gc.minTTL)
+		return gc.minTTL
+	case gc.maxTTL > 0 && endpoint.TTL > gc.maxTTL:
+		log.Warnf("[Cloud DNS] TTL %d of %s is above the maximum TTL %d", endpoint.TTL, endpoint.Hostname, gc.maxTTL)
+		return gc.maxTTL
+	}
+	return endpoint.TTL
+}
+
+// validateTTLBounds checks that TTL bounds are not negative and the minimum doesn't exceed the maximum.
+// The maximum is unlimited when zero.
+func validateTTLBounds(minimum int64, maximum int64) error {
+	if minimum < 0 || maximum < 0 || (maximum > 0 && minimum > maximum) {
+		return fmt.Errorf("Invalid TTL bounds: --dns-min-ttl %d must be between 0 and --dns-max-ttl %d", minimum, maximum)
+	}
+	return nil
+}
+
+// minTTL returns the lower TTL, records contributed by several endpoints have the lowest TTL of the endpoints
+func minTTL(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
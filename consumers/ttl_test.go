@@ -0,0 +1,67 @@
+package consumers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/dns/v1"
+	"testing"
+)
+
+func TestEndpointTTL(t *testing.T) {
+	a := assert.New(t)
+
+	gc := &GoogleConsumer{
+		dnsTTL:           300,
+		minTTL:           30,
+		maxTTL:           3600,
+		dnsZones:         map[string]struct{}{"internal-example-com": {}},
+		multipleIPRecord: true,
+		dnsService: &fakeDNSService{
+			projectDNSZones: map[string]string{"internal-example-com": "internal.example.com."},
+			managedZoneRRS:  map[string][]*dns.ResourceRecordSet{},
+		},
+	}
+	endpoints := []*pkg.Endpoint{
+		// default TTL
+		{Hostname: "vm-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "europe-west1-c"},
+		// the lowest TTL of the endpoints
+		{Hostname: "web", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "europe-west1-c", TTL: 120},
+		{Hostname: "web", DNSZone: "internal-example-com", IP: "10.132.0.3", ComputeZone: "europe-west1-c"},
+		{Hostname: "web", DNSZone: "internal-example-com", IP: "10.132.0.4", ComputeZone: "europe-west1-c", TTL: 60},
+		// TTL bounds
+		{Hostname: "cache", DNSZone: "internal-example-com", IP: "10.132.0.5", ComputeZone: "europe-west1-c", TTL: 5},
+		{Hostname: "db", DNSZone: "internal-example-com", IP: "10.132.0.6", ComputeZone: "europe-west1-c", TTL: 86400},
+		{Hostname: "_postgres._tcp", DNSZone: "internal-example-com", Type: pkg.RecordTypeSRV, Target: "db", Port: 5432, ComputeZone: "europe-west1-c", TTL: 600},
+		{Hostname: "db-primary", DNSZone: "internal-example-com", Type: pkg.RecordTypeCNAME, Target: "db", ComputeZone: "europe-west1-c", TTL: 900},
+	}
+	recordGroups, err := gc.endpointsRecordGroups([]string{"europe-west1-c"}, endpoints)
+	a.NoError(err)
+
+	ttls := make(map[string]int64)
+	for _, v := range recordGroups {
+		ttls[v.DNSName] = v.TTL
+	}
+	a.Equal(map[string]int64{
+		"vm-1.internal.example.com.":           300,
+		"web.internal.example.com.":            60,
+		"cache.internal.example.com.":          30,
+		"db.internal.example.com.":             3600,
+		"_postgres._tcp.internal.example.com.": 600,
+		"db-primary.internal.example.com.":     900,
+	}, ttls)
+
+	// unlimited maximum TTL
+	gc.maxTTL = 0
+	a.Equal(int64(86400), gc.endpointTTL(&pkg.Endpoint{TTL: 86400}))
+}
+
+func TestValidateTTLBounds(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(validateTTLBounds(30, 86400))
+	a.NoError(validateTTLBounds(30, 0), "maximum is unlimited")
+	a.NoError(validateTTLBounds(0, 0))
+	a.Error(validateTTLBounds(3600, 60))
+	a.Error(validateTTLBounds(-1, 60))
+	a.Error(validateTTLBounds(30, -1))
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/everesio/buddy/consumers"
@@ -12,9 +13,13 @@ import (
 )
 
 var (
+	// interval between checks of the leadership, the producer is watched only while being the leader
+	watchLeaderCheckPeriod = DefaultLeaderElectionRetryPeriod
+
 	synchronizeProcessingTimeSummary prometheus.Summary
 	synchronizePendingOpsGauge       prometheus.Gauge
 	synchronizeErrorCounter          prometheus.Counter
+	synchronizeTriggeredCounter      prometheus.Counter
 )
 
 func init() {
@@ -42,10 +47,22 @@ func init() {
 	})
 	prometheus.MustRegister(synchronizeErrorCounter)
 
+	synchronizeTriggeredCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "synchronize",
+		Name:      "triggered_count",
+		Help:      "Number of synchronizations triggered by producer changes.",
+	})
+	prometheus.MustRegister(synchronizeTriggeredCounter)
+
 }
 
 type Options struct {
 	SyncInterval time.Duration
+	// only the leader synchronizes records, when configured
+	LeaderElector LeaderElector
+	// changes signaled by the producer within the period are synchronized together
+	Debounce time.Duration
 }
 
 type Controller struct {
@@ -53,6 +70,8 @@ type Controller struct {
 	consumer consumers.Consumer
 	options  *Options
 	stop     chan error
+	done     chan struct{}
+	trigger  chan struct{}
 	wg       sync.WaitGroup
 }
 
@@ -65,25 +84,123 @@ func New(producer producers.Producer, consumer consumers.Consumer, options *Opti
 		consumer: consumer,
 		options:  options,
 		stop:     stop,
+		done:     make(chan struct{}),
+		trigger:  make(chan struct{}, 1),
 	}
 }
 
 func (c *Controller) Run() {
-	if c.options.SyncInterval > 0 {
+	if c.options.LeaderElector != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.options.LeaderElector.Run(c.done)
+		}()
+	}
+	watcher, watching := c.producer.(producers.Watcher)
+	if watching {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.watch(watcher)
+		}()
+	}
+	switch {
+	case c.options.SyncInterval > 0:
 		c.syncLoop()
-	} else {
+	case watching:
+		log.Warn("[Synchronize] Periodic synchronization is disabled, records are synchronized only when triggered.")
+		c.syncLoop()
+	default:
 		log.Warn("[Synchronize] Synchronization loop is disabled.")
 	}
 }
 
+// Stop exits the synchronization loop and gives up the leadership
+func (c *Controller) Stop() {
+	close(c.done)
+	c.wg.Wait()
+}
+
+// IsLeader returns true when the controller is allowed to synchronize records
+func (c *Controller) IsLeader() bool {
+	return c.options.LeaderElector == nil || c.options.LeaderElector.IsLeader()
+}
+
+// watch runs the watcher while the controller is the leader. A new leader synchronizes, because changes were not
+// watched before.
+func (c *Controller) watch(watcher producers.Watcher) {
+	if c.options.LeaderElector == nil {
+		watcher.Watch(c.trigger, c.done)
+		return
+	}
+	var stop chan struct{}
+	var watching sync.WaitGroup
+	for {
+		leader := c.IsLeader()
+		switch {
+		case leader && stop == nil:
+			log.Info("[Synchronize] Watching producer changes as the leader.")
+			stop = make(chan struct{})
+			watching.Add(1)
+			go func(stop <-chan struct{}) {
+				defer watching.Done()
+				watcher.Watch(c.trigger, stop)
+			}(stop)
+			c.Trigger()
+		case !leader && stop != nil:
+			log.Info("[Synchronize] Stopped watching producer changes, not the leader.")
+			close(stop)
+			watching.Wait()
+			stop = nil
+		}
+		select {
+		case <-time.After(watchLeaderCheckPeriod):
+		case <-c.done:
+			if stop != nil {
+				close(stop)
+			}
+			watching.Wait()
+			return
+		}
+	}
+}
+
+// Trigger requests synchronization before the end of the sync interval
+func (c *Controller) Trigger() {
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// syncLoop synchronizes every sync interval and when triggered. The periodic synchronization is a full resync
+// covering changes missed by the producer, it is disabled when the sync interval is not positive.
 func (c *Controller) syncLoop() {
 	for {
-		log.Debugf("[Synchronize] Sleeping for %s...", c.options.SyncInterval)
+		var interval <-chan time.Time
+		if c.options.SyncInterval > 0 {
+			log.Debugf("[Synchronize] Sleeping for %s...", c.options.SyncInterval)
+			interval = time.After(c.options.SyncInterval)
+		}
 		select {
-		case <-time.After(c.options.SyncInterval):
+		case <-interval:
+		case <-c.trigger:
+			if !c.debounce() {
+				log.Info("[Synchronize] Exited synchronization loop.")
+				return
+			}
+			synchronizeTriggeredCounter.Inc()
 		case <-c.stop:
 			log.Info("[Synchronize] Exited synchronization loop.")
 			return
+		case <-c.done:
+			log.Info("[Synchronize] Exited synchronization loop.")
+			return
+		}
+		if !c.IsLeader() {
+			log.Debug("[Synchronize] Skipped, not the leader.")
+			continue
 		}
 		err := c.Synchronize()
 		if err != nil {
@@ -92,7 +209,26 @@ func (c *Controller) syncLoop() {
 	}
 }
 
+// debounce waits for the debounce period absorbing further triggers. It returns false, when the controller was stopped.
+func (c *Controller) debounce() bool {
+	log.Debugf("[Synchronize] Triggered, waiting %s for further changes...", c.options.Debounce)
+	deadline := time.After(c.options.Debounce)
+	for {
+		select {
+		case <-deadline:
+			return true
+		case <-c.trigger:
+		case <-c.done:
+			return false
+		}
+	}
+}
+
 func (c *Controller) Synchronize() error {
+	if !c.IsLeader() {
+		return errors.New("[Synchronize] Not the leader")
+	}
+
 	timer := pkg.NewTimer(prometheus.ObserverFunc(func(v float64) {
 		synchronizeProcessingTimeSummary.Observe(v)
 	}))
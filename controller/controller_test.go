@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeWatchingProducer struct {
+	fakeProducer
+	changes chan<- struct{}
+	started chan struct{}
+}
+
+func (p *fakeWatchingProducer) Watch(changes chan<- struct{}, stop <-chan struct{}) {
+	p.changes = changes
+	close(p.started)
+	<-stop
+}
+
+func (p *fakeWatchingProducer) signal() {
+	select {
+	case p.changes <- struct{}{}:
+	default:
+	}
+}
+
+func TestTriggeredSynchronization(t *testing.T) {
+	a := assert.New(t)
+
+	producer := &fakeWatchingProducer{started: make(chan struct{})}
+	consumer := &fakeConsumer{}
+	ctrl := New(producer, consumer, &Options{SyncInterval: time.Hour, Debounce: 50 * time.Millisecond}, nil)
+	go ctrl.Run()
+	<-producer.started
+
+	// changes within the debounce period are synchronized together
+	producer.signal()
+	time.Sleep(10 * time.Millisecond)
+	producer.signal()
+	a.True(eventually(func() bool { return consumer.count() == 1 }))
+	time.Sleep(100 * time.Millisecond)
+	a.Equal(1, consumer.count())
+
+	ctrl.Trigger()
+	a.True(eventually(func() bool { return consumer.count() == 2 }))
+
+	ctrl.Stop()
+}
+
+func TestTriggeredSynchronizationWithoutSyncInterval(t *testing.T) {
+	a := assert.New(t)
+
+	producer := &fakeWatchingProducer{started: make(chan struct{})}
+	consumer := &fakeConsumer{}
+	ctrl := New(producer, consumer, &Options{Debounce: 10 * time.Millisecond}, nil)
+	go ctrl.Run()
+	<-producer.started
+
+	producer.signal()
+	a.True(eventually(func() bool { return consumer.count() == 1 }))
+
+	ctrl.Stop()
+}
+
+type fakeLeaderElector struct {
+	leaderState
+}
+
+func (e *fakeLeaderElector) Run(stop <-chan struct{}) {
+	<-stop
+}
+
+type countingWatchProducer struct {
+	fakeProducer
+	watching int32
+}
+
+func (p *countingWatchProducer) Watch(changes chan<- struct{}, stop <-chan struct{}) {
+	atomic.AddInt32(&p.watching, 1)
+	defer atomic.AddInt32(&p.watching, -1)
+	<-stop
+}
+
+func (p *countingWatchProducer) count() int {
+	return int(atomic.LoadInt32(&p.watching))
+}
+
+func TestWatchOnlyAsLeader(t *testing.T) {
+	a := assert.New(t)
+
+	defer func(period time.Duration) { watchLeaderCheckPeriod = period }(watchLeaderCheckPeriod)
+	watchLeaderCheckPeriod = 10 * time.Millisecond
+
+	producer := &countingWatchProducer{}
+	consumer := &fakeConsumer{}
+	elector := &fakeLeaderElector{}
+	ctrl := New(producer, consumer, &Options{Debounce: 10 * time.Millisecond, LeaderElector: elector}, nil)
+	go ctrl.Run()
+
+	time.Sleep(50 * time.Millisecond)
+	a.Equal(0, producer.count())
+
+	// the new leader watches and synchronizes
+	elector.setLeader(true)
+	a.True(eventually(func() bool { return producer.count() == 1 }))
+	a.True(eventually(func() bool { return consumer.count() == 1 }))
+
+	elector.setLeader(false)
+	a.True(eventually(func() bool { return producer.count() == 0 }))
+
+	elector.setLeader(true)
+	a.True(eventually(func() bool { return producer.count() == 1 }))
+
+	ctrl.Stop()
+	a.Equal(0, producer.count())
+}
@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultLeaderElectionRetryPeriod is the interval between attempts to acquire the leadership
+	DefaultLeaderElectionRetryPeriod = 2 * time.Second
+)
+
+var (
+	leaderGauge prometheus.Gauge
+)
+
+func init() {
+	leaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "leader_election",
+		Name:      "is_leader",
+		Help:      "1 when the instance is the leader, 0 otherwise.",
+	})
+	prometheus.MustRegister(leaderGauge)
+}
+
+// LeaderElector decides which of the running instances synchronizes records
+type LeaderElector interface {
+	// Run campaigns for leadership until stop is closed
+	Run(stop <-chan struct{})
+	// IsLeader returns true when the instance holds the leadership
+	IsLeader() bool
+}
+
+// leaderState is shared by the leader elector backends
+type leaderState struct {
+	leader int32
+}
+
+func (s *leaderState) IsLeader() bool {
+	return atomic.LoadInt32(&s.leader) == 1
+}
+
+func (s *leaderState) setLeader(leader bool) {
+	var v int32
+	if leader {
+		v = 1
+	}
+	if atomic.SwapInt32(&s.leader, v) != v {
+		leaderGauge.Set(float64(v))
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"os"
+	"syscall"
+	"time"
+)
+
+// FileLeaderElector holds an exclusive lock of the local file while being the leader.
+// It is meant for instances running on the same host and for tests.
+type FileLeaderElector struct {
+	leaderState
+	path        string
+	retryPeriod time.Duration
+}
+
+// NewFileLeaderElector creates new FileLeaderElector
+func NewFileLeaderElector(path string, retryPeriod time.Duration) (*FileLeaderElector, error) {
+	if path == "" {
+		return nil, fmt.Errorf("[Leader election] Lock file is required")
+	}
+	return &FileLeaderElector{path: path, retryPeriod: retryPeriod}, nil
+}
+
+// Run tries to acquire the lock every retry period and holds it until stop is closed
+func (e *FileLeaderElector) Run(stop <-chan struct{}) {
+	for {
+		file, err := e.tryLock()
+		if err != nil {
+			log.Debugf("[Leader election] Lock %s was not acquired: %v", e.path, err)
+		} else {
+			log.Infof("[Leader election] Acquired lock %s", e.path)
+			e.setLeader(true)
+			<-stop
+			e.setLeader(false)
+			syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+			file.Close()
+			log.Infof("[Leader election] Released lock %s", e.path)
+			return
+		}
+		select {
+		case <-time.After(e.retryPeriod):
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (e *FileLeaderElector) tryLock() (*os.File, error) {
+	file, err := os.OpenFile(e.path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"golang.org/x/net/context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"time"
+)
+
+const (
+	leaseDuration = 15 * time.Second
+	renewDeadline = 10 * time.Second
+)
+
+// KubernetesLeaderElector holds a kubernetes Lease while being the leader
+type KubernetesLeaderElector struct {
+	leaderState
+	config leaderelection.LeaderElectionConfig
+}
+
+// NewKubernetesLeaderElector creates new KubernetesLeaderElector using the Lease namespace/name
+func NewKubernetesLeaderElector(client kubernetes.Interface, namespace string, name string, identity string) (*KubernetesLeaderElector, error) {
+	if namespace == "" || name == "" || identity == "" {
+		return nil, fmt.Errorf("[Leader election] Lease namespace, name and identity are required")
+	}
+	e := &KubernetesLeaderElector{}
+	e.config = leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     DefaultLeaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("[Leader election] %s acquired lease %s/%s", identity, namespace, name)
+				e.setLeader(true)
+			},
+			OnStoppedLeading: func() {
+				log.Infof("[Leader election] %s released lease %s/%s", identity, namespace, name)
+				e.setLeader(false)
+			},
+		},
+	}
+	if _, err := leaderelection.NewLeaderElector(e.config); err != nil {
+		return nil, fmt.Errorf("[Leader election] Invalid configuration: %v", err)
+	}
+	return e, nil
+}
+
+// Run campaigns for the lease until stop is closed. Lost leadership is campaigned for again.
+func (e *KubernetesLeaderElector) Run(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+	for {
+		leaderelection.RunOrDie(ctx, e.config)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(DefaultLeaderElectionRetryPeriod):
+		}
+	}
+}
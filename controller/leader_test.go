@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeProducer struct{}
+
+func (p *fakeProducer) ComputeZones() []string {
+	return []string{"europe-west1-c"}
+}
+
+func (p *fakeProducer) Endpoints() ([]*pkg.Endpoint, error) {
+	return []*pkg.Endpoint{}, nil
+}
+
+type fakeConsumer struct {
+	syncs int32
+}
+
+func (c *fakeConsumer) Sync(computeZones []string, endpoints []*pkg.Endpoint) error {
+	atomic.AddInt32(&c.syncs, 1)
+	return nil
+}
+
+func (c *fakeConsumer) count() int {
+	return int(atomic.LoadInt32(&c.syncs))
+}
+
+func (c *fakeConsumer) Records(computeZones []string) (interface{}, error) {
+	return nil, nil
+}
+
+func eventually(condition func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestFileLeaderElector(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "buddy.lock")
+	e1, err := NewFileLeaderElector(path, 10*time.Millisecond)
+	a.NoError(err)
+	e2, err := NewFileLeaderElector(path, 10*time.Millisecond)
+	a.NoError(err)
+
+	stop1 := make(chan struct{})
+	done1 := make(chan struct{})
+	go func() {
+		e1.Run(stop1)
+		close(done1)
+	}()
+	a.True(eventually(e1.IsLeader))
+
+	stop2 := make(chan struct{})
+	done2 := make(chan struct{})
+	go func() {
+		e2.Run(stop2)
+		close(done2)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	a.False(e2.IsLeader())
+
+	// e2 takes over the leadership
+	close(stop1)
+	<-done1
+	a.False(e1.IsLeader())
+	a.True(eventually(e2.IsLeader))
+
+	close(stop2)
+	<-done2
+	a.False(e2.IsLeader())
+}
+
+func TestNewFileLeaderElectorWithoutPath(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := NewFileLeaderElector("", time.Second)
+	a.Error(err)
+}
+
+func TestFollowerDoesNotSynchronize(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "buddy.lock")
+	leaderElector, err := NewFileLeaderElector(path, 10*time.Millisecond)
+	a.NoError(err)
+	followerElector, err := NewFileLeaderElector(path, 10*time.Millisecond)
+	a.NoError(err)
+
+	leaderConsumer := &fakeConsumer{}
+	leader := New(&fakeProducer{}, leaderConsumer, &Options{LeaderElector: leaderElector}, nil)
+	go leader.Run()
+	a.True(eventually(leader.IsLeader))
+
+	followerConsumer := &fakeConsumer{}
+	follower := New(&fakeProducer{}, followerConsumer, &Options{LeaderElector: followerElector}, nil)
+	go follower.Run()
+	time.Sleep(50 * time.Millisecond)
+
+	a.NoError(leader.Synchronize())
+	a.Error(follower.Synchronize())
+	a.Equal(1, leaderConsumer.count())
+	a.Equal(0, followerConsumer.count())
+
+	leader.Stop()
+	a.True(eventually(follower.IsLeader))
+	a.NoError(follower.Synchronize())
+	a.Equal(1, followerConsumer.count())
+	follower.Stop()
+}
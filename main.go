@@ -26,9 +26,26 @@ var params struct {
 	consumer     string
 	debug        bool
 	syncInterval int
+	syncDebounce int
 	jsonLog      bool
+	dryRun       bool
+
+	leaderElection          string
+	leaderElectionLockFile  string
+	leaderElectionNamespace string
+	leaderElectionName      string
+	leaderElectionID        string
+
+	migrateFromPrefix  string
+	migrateFromOwnerID string
+	migrateApply       bool
 }
 
+const (
+	runCommand           = "run"
+	migrateLabelsCommand = "migrate-labels"
+)
+
 func init() {
 	kingpin.Flag("http-addr", "HTTP listen address").Default(":8080").StringVar(&params.httpAddr)
 	kingpin.Flag("debug-addr", "Debug listen address").Default(":8081").StringVar(&params.debugAddr)
@@ -36,12 +53,25 @@ func init() {
 	kingpin.Flag("consumer", "The endpoints consumer to use.").Default("google").StringVar(&params.consumer)
 	kingpin.Flag("debug", "Enable debug logging.").BoolVar(&params.debug)
 	kingpin.Flag("sync-interval", "Sync interval in seconds.").Default("15").IntVar(&params.syncInterval)
+	kingpin.Flag("sync-debounce", "Delay in seconds of synchronization triggered by producer changes.").Default("1").IntVar(&params.syncDebounce)
 	kingpin.Flag("json-log", "Enable json log formatter.").BoolVar(&params.jsonLog)
+	kingpin.Flag("dry-run", "Log planned changes without applying them.").BoolVar(&params.dryRun)
+	kingpin.Flag("leader-election", "Leader election backend: none, kubernetes or file.").Default("none").EnumVar(&params.leaderElection, "none", "kubernetes", "file")
+	kingpin.Flag("leader-election-lock-file", "Lock file used by the file leader election.").Default("/tmp/buddy.lock").StringVar(&params.leaderElectionLockFile)
+	kingpin.Flag("leader-election-namespace", "Namespace of the Lease used by the kubernetes leader election.").Default("default").StringVar(&params.leaderElectionNamespace)
+	kingpin.Flag("leader-election-name", "Name of the Lease used by the kubernetes leader election.").Default("buddy").StringVar(&params.leaderElectionName)
+	kingpin.Flag("leader-election-id", "Identity of the instance, hostname when empty.").StringVar(&params.leaderElectionID)
+
+	kingpin.Command(runCommand, "Synchronize DNS records.").Default()
+	migrate := kingpin.Command(migrateLabelsCommand, "Rewrite TXT records created with another label prefix or owner ID to --buddy-label-prefix and --owner-id.")
+	migrate.Flag("from-prefix", "Label prefix of the records to migrate, --buddy-label-prefix when empty.").StringVar(&params.migrateFromPrefix)
+	migrate.Flag("from-owner-id", "Owner ID of the records to migrate, labels without owner ID when empty.").StringVar(&params.migrateFromOwnerID)
+	migrate.Flag("apply", "Apply the changes, only the report is printed otherwise.").BoolVar(&params.migrateApply)
 }
 
 func main() {
 	kingpin.Version(version)
-	kingpin.Parse()
+	command := kingpin.Parse()
 
 	var formatter log.Formatter
 	if params.jsonLog {
@@ -54,13 +84,22 @@ func main() {
 		log.SetLevel(log.DebugLevel)
 	}
 
+	if command == migrateLabelsCommand {
+		migrateLabels()
+		return
+	}
+
 	log.Info("Starting buddy")
 
 	producer, err := producers.New(params.producer)
 	if err != nil {
 		log.Fatalf("Error creating producer: %v", err)
 	}
-	consumer, err := consumers.NewSynced(params.consumer)
+	consumerName := params.consumer
+	if params.dryRun {
+		consumerName += consumers.DryRunSuffix
+	}
+	consumer, err := consumers.NewSynced(consumerName)
 	if err != nil {
 		log.Fatalf("Error creating consumer: %v", err)
 	}
@@ -74,8 +113,15 @@ func main() {
 		errc <- fmt.Errorf("%s", <-c)
 	}()
 
+	leaderElector, err := newLeaderElector()
+	if err != nil {
+		log.Fatalf("Error creating leader elector: %v", err)
+	}
+
 	opts := &controller.Options{
-		SyncInterval: time.Duration(params.syncInterval) * time.Second,
+		SyncInterval:  time.Duration(params.syncInterval) * time.Second,
+		LeaderElector: leaderElector,
+		Debounce:      time.Duration(params.syncDebounce) * time.Second,
 	}
 	ctrl := controller.New(producer, consumer, opts, errc)
 
@@ -98,6 +144,7 @@ func main() {
 		http.Handle("/endpoints", endpointsHandler(producer))
 		http.Handle("/records", recordsHandler(producer, consumer))
 		http.Handle("/sync", syncHandler(ctrl))
+		http.Handle("/plan", planHandler(producer, consumer))
 		log.Info("HTTP addr ", params.httpAddr)
 		errc <- http.ListenAndServe(params.httpAddr, nil)
 	}()
@@ -108,12 +155,57 @@ func main() {
 	}()
 
 	// Run!
-	log.Info("exit", <-errc)
+	err = <-errc
+	ctrl.Stop()
+	log.Info("exit", err)
+}
+
+func migrateLabels() {
+	consumer, err := consumers.New(params.consumer)
+	if err != nil {
+		log.Fatalf("Error creating consumer: %v", err)
+	}
+	migrator, ok := consumer.(consumers.LabelMigrator)
+	if !ok {
+		log.Fatalf("Consumer '%s' does not support label migration", params.consumer)
+	}
+	plan, err := migrator.MigrateLabels(params.migrateFromPrefix, params.migrateFromOwnerID, params.migrateApply)
+	if plan != nil {
+		fmt.Print(plan.String())
+	}
+	if err != nil {
+		log.Fatalf("Error migrating labels: %v", err)
+	}
+	if !params.migrateApply && !plan.Empty() {
+		fmt.Println("Run with --apply to apply the changes.")
+	}
+}
+
+func newLeaderElector() (controller.LeaderElector, error) {
+	switch params.leaderElection {
+	case "file":
+		return controller.NewFileLeaderElector(params.leaderElectionLockFile, controller.DefaultLeaderElectionRetryPeriod)
+	case "kubernetes":
+		identity := params.leaderElectionID
+		if identity == "" {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, err
+			}
+			identity = hostname
+		}
+		client, err := producers.NewKubernetesClient()
+		if err != nil {
+			return nil, err
+		}
+		return controller.NewKubernetesLeaderElector(client, params.leaderElectionNamespace, params.leaderElectionName, identity)
+	}
+	return nil, nil
 }
 
 func endpointsHandler(producer producers.Producer) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		endpoints, err := producer.Endpoints()
+		endpoints, err := producers.PreviewEndpoints(producer)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -133,6 +225,31 @@ func recordsHandler(producer producers.Producer, consumer consumers.Consumer) ht
 	})
 }
 
+func planHandler(producer producers.Producer, consumer consumers.Consumer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		planner, ok := consumer.(consumers.Planner)
+		if !ok {
+			http.Error(w, "Consumer does not support planning", http.StatusNotImplemented)
+			return
+		}
+		endpoints, err := producers.PreviewEndpoints(producer)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		plan, err := planner.Plan(producer.ComputeZones(), endpoints)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if req.URL.Query().Get("format") == "text" {
+			fmt.Fprint(w, plan)
+			return
+		}
+		json.NewEncoder(w).Encode(plan)
+	})
+}
+
 func syncHandler(controller *controller.Controller) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		err := controller.Synchronize()
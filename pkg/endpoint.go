@@ -1,5 +1,22 @@
 package pkg
 
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+const (
+	// RecordTypeA is the record type of IPv4 endpoints
+	RecordTypeA = "A"
+	// RecordTypeAAAA is the record type of IPv6 endpoints
+	RecordTypeAAAA = "AAAA"
+	// RecordTypeCNAME is the record type of alias endpoints
+	RecordTypeCNAME = "CNAME"
+	// RecordTypeSRV is the record type of service endpoints
+	RecordTypeSRV = "SRV"
+)
+
 // Endpoint is used to pass data from the producer to the consumer.
 type Endpoint struct {
 
@@ -9,9 +26,85 @@ type Endpoint struct {
 	// Google Cloud DNS zone name to be used by the consumer for the record.
 	DNSZone string `json:"dnsZone"`
 
-	// IPv4 address.
+	// IPv4 or IPv6 address, empty for CNAME.
 	IP string `json:"ip"`
 
+	// Record type A, AAAA, CNAME or SRV. A or AAAA is derived from IP when empty.
+	Type string `json:"type,omitempty"`
+
+	// Hostname in the same zone the CNAME or SRV points at.
+	Target string `json:"target,omitempty"`
+
+	// SRV port, priority and weight
+	Port     int `json:"port,omitempty"`
+	Priority int `json:"priority,omitempty"`
+	Weight   int `json:"weight,omitempty"`
+
 	// Compute engine zone
 	ComputeZone string `json:"computeZone"`
+
+	// Google project of the compute resource, optional
+	Project string `json:"project,omitempty"`
+
+	// TTL in seconds overriding the consumer TTL, the consumer TTL is used when zero
+	TTL int64 `json:"ttl,omitempty"`
+}
+
+// RecordType returns the record type of the endpoint: A for IPv4 and AAAA for IPv6 address
+func (e *Endpoint) RecordType() string {
+	if e.Type != "" {
+		return e.Type
+	}
+	if ip := net.ParseIP(e.IP); ip != nil && ip.To4() == nil {
+		return RecordTypeAAAA
+	}
+	return RecordTypeA
+}
+
+// Validate checks that the endpoint can be used to create a record
+func (e *Endpoint) Validate() error {
+	if e.TTL < 0 {
+		return fmt.Errorf("invalid TTL %d", e.TTL)
+	}
+	if e.RecordType() == RecordTypeCNAME {
+		if e.Hostname == "" || e.ComputeZone == "" || e.DNSZone == "" || e.Target == "" {
+			return errors.New("hostname, dnsZone, target and computeZone are required")
+		}
+		if e.IP != "" {
+			return errors.New("ip must be empty for CNAME")
+		}
+		return nil
+	}
+	if e.RecordType() == RecordTypeSRV {
+		if e.Hostname == "" || e.ComputeZone == "" || e.DNSZone == "" || e.Target == "" {
+			return errors.New("hostname, dnsZone, target and computeZone are required")
+		}
+		if e.IP != "" {
+			return errors.New("ip must be empty for SRV")
+		}
+		if e.Port <= 0 || e.Port > 65535 || e.Priority < 0 || e.Priority > 65535 || e.Weight < 0 || e.Weight > 65535 {
+			return fmt.Errorf("invalid SRV port %d, priority %d or weight %d", e.Port, e.Priority, e.Weight)
+		}
+		return nil
+	}
+	if e.Hostname == "" || e.ComputeZone == "" || e.DNSZone == "" || e.IP == "" {
+		return errors.New("hostname, dnsZone, ip and computeZone are required")
+	}
+	ip := net.ParseIP(e.IP)
+	if ip == nil {
+		return fmt.Errorf("invalid IP address '%s'", e.IP)
+	}
+	switch e.RecordType() {
+	case RecordTypeA:
+		if ip.To4() == nil {
+			return fmt.Errorf("invalid IPv4 address '%s'", e.IP)
+		}
+	case RecordTypeAAAA:
+		if ip.To4() != nil {
+			return fmt.Errorf("invalid IPv6 address '%s'", e.IP)
+		}
+	default:
+		return fmt.Errorf("unsupported record type '%s'", e.Type)
+	}
+	return nil
 }
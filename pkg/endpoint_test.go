@@ -0,0 +1,37 @@
+package pkg
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEndpointValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		endpoint   Endpoint
+		recordType string
+		valid      bool
+	}{
+		{name: "IPv4", endpoint: Endpoint{IP: "10.0.0.1"}, recordType: RecordTypeA, valid: true},
+		{name: "IPv6", endpoint: Endpoint{IP: "2600:1900::1"}, recordType: RecordTypeAAAA, valid: true},
+		{name: "explicit type", endpoint: Endpoint{IP: "2600:1900::1", Type: RecordTypeAAAA}, recordType: RecordTypeAAAA, valid: true},
+		{name: "type mismatch", endpoint: Endpoint{IP: "10.0.0.1", Type: RecordTypeAAAA}, recordType: RecordTypeAAAA},
+		{name: "unsupported type", endpoint: Endpoint{IP: "10.0.0.1", Type: "MX"}, recordType: "MX"},
+		{name: "CNAME", endpoint: Endpoint{Type: RecordTypeCNAME, Target: "vm-1"}, recordType: RecordTypeCNAME, valid: true},
+		{name: "CNAME with IP", endpoint: Endpoint{IP: "10.0.0.1", Type: RecordTypeCNAME, Target: "vm-1"}, recordType: RecordTypeCNAME},
+		{name: "CNAME without target", endpoint: Endpoint{Type: RecordTypeCNAME}, recordType: RecordTypeCNAME},
+		{name: "SRV", endpoint: Endpoint{Type: RecordTypeSRV, Target: "vm-1", Port: 5432, Priority: 10, Weight: 5}, recordType: RecordTypeSRV, valid: true},
+		{name: "SRV without port", endpoint: Endpoint{Type: RecordTypeSRV, Target: "vm-1"}, recordType: RecordTypeSRV},
+		{name: "SRV with invalid weight", endpoint: Endpoint{Type: RecordTypeSRV, Target: "vm-1", Port: 5432, Weight: 65536}, recordType: RecordTypeSRV},
+		{name: "invalid IP", endpoint: Endpoint{IP: "10.0.0.256"}, recordType: RecordTypeA},
+		{name: "TTL", endpoint: Endpoint{IP: "10.0.0.1", TTL: 30}, recordType: RecordTypeA, valid: true},
+		{name: "negative TTL", endpoint: Endpoint{IP: "10.0.0.1", TTL: -1}, recordType: RecordTypeA},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := tc.endpoint
+			e.Hostname, e.DNSZone, e.ComputeZone = "vm", "internal-zone", "zone-a"
+			assert.Equal(t, tc.recordType, e.RecordType())
+			assert.Equal(t, tc.valid, e.Validate() == nil)
+		})
+	}
+}
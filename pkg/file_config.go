@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// FileConfig provides configuration of file producer
+var FileConfig struct {
+	Path string
+	// in seconds
+	WatchInterval int
+}
+
+func init() {
+	kingpin.Flag("endpoints-file", "Path to YAML or JSON file with compute zones and endpoints").StringVar(&FileConfig.Path)
+	kingpin.Flag("endpoints-file-watch-interval", "Interval in seconds to check the endpoints file for changes").Default("10").IntVar(&FileConfig.WatchInterval)
+}
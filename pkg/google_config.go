@@ -2,6 +2,8 @@ package pkg
 
 import (
 	"gopkg.in/alecthomas/kingpin.v2"
+	"strings"
+	"time"
 )
 
 const (
@@ -10,26 +12,151 @@ const (
 
 // GoogleConfig provides configuration of google producer and consumer
 var GoogleConfig struct {
-	Project           string
-	Zone              string
-	Region            string
+	Project string
+	// comma separated projects of compute resources, Project when empty
+	ComputeProjects string
+	// project of DNS managed zones, Project when empty
+	DNSProject string
+	Zone       string
+	Region     string
+	// all zones of the projects are managed
+	AllZones bool
+	// maximum number of compute zones listed in parallel
+	ZoneConcurrency int
+	// regional and global forwarding rules are listed
+	ForwardingRules   bool
 	ExternalIPDNSZone string
 	InternalIPDNSZone string
 	DNSTTL            int64
+	// bounds of TTL overrides of instances
+	DNSMinTTL int64
+	DNSMaxTTL int64
 	// additional zones not configured by ExternalIPDNSZone and InternalIPDNSZone
 	DNSZones         string
 	MultipleIPRecord bool
+	// PTR records are managed in reverse zones among DNSZones
+	PTRRecords       bool
 	BuddyLabelPrefix string
+	// identifier of the deployment embedded in TXT labels
+	OwnerID           string
+	AdoptLegacyLabels bool
+	ChangeBatchSize   int
+	// deletion guard is disabled when zero
+	MaxDeletions        int
+	MaxDeletionsPercent float64
+	ForceDeletions      bool
+	// sync, upsert-only or create-only
+	Policy string
+	// retries of google API calls
+	APIRetries int
+	// in milliseconds
+	APIRetryInitialBackoff int
+	APIRetryMaxBackoff     int
+	WatchOperations        bool
+	// in seconds
+	WatchInterval int
+	// go template of default hostnames
+	HostnameTemplate string
+	// comma separated instance metadata, tags and labels in the order of precedence
+	KeyPrecedence string
+	// comma separated allowed instance statuses, all statuses are allowed when empty
+	InstanceStatuses string
+	// in seconds
+	InstanceStatusGracePeriod int
+	// added to default hostnames of alias IPs
+	AliasHostnameSuffix string
 }
 
 func init() {
 	kingpin.Flag("google-project", "Project ID that manages the zone").StringVar(&GoogleConfig.Project)
+	kingpin.Flag("google-compute-projects", "Comma separated project IDs of compute resources, google-project is used when empty").StringVar(&GoogleConfig.ComputeProjects)
+	kingpin.Flag("google-dns-project", "Project ID of DNS managed zones, google-project is used when empty").StringVar(&GoogleConfig.DNSProject)
 	kingpin.Flag("google-zone", "Name of the google compute zone to manage").StringVar(&GoogleConfig.Zone)
 	kingpin.Flag("google-region", "Name of the google compute region to manage").StringVar(&GoogleConfig.Region)
+	kingpin.Flag("google-all-zones", "Manage all compute zones of the projects, instances are listed using the aggregated list").BoolVar(&GoogleConfig.AllZones)
+	kingpin.Flag("google-zone-concurrency", "Maximum number of compute zones listed in parallel").Default("4").IntVar(&GoogleConfig.ZoneConcurrency)
+	kingpin.Flag("google-forwarding-rules", "Manage records of regional forwarding rules in managed regions and global forwarding rules").BoolVar(&GoogleConfig.ForwardingRules)
 	kingpin.Flag("external-ip-dns-zone", "Default DNS managed zone name for external IPs").StringVar(&GoogleConfig.ExternalIPDNSZone)
 	kingpin.Flag("internal-ip-dns-zone", "Default DNS managed zone name for internal IPs").StringVar(&GoogleConfig.InternalIPDNSZone)
 	kingpin.Flag("dns-ttl", "TTL in seconds for managed DNS resource records").Default("300").Int64Var(&GoogleConfig.DNSTTL)
+	kingpin.Flag("dns-min-ttl", "Minimum TTL in seconds of dns-ttl overrides of instances").Default("30").Int64Var(&GoogleConfig.DNSMinTTL)
+	kingpin.Flag("dns-max-ttl", "Maximum TTL in seconds of dns-ttl overrides of instances").Default("86400").Int64Var(&GoogleConfig.DNSMaxTTL)
 	kingpin.Flag("dns-zones", "Comma separated names of DNS managed zones").StringVar(&GoogleConfig.DNSZones)
 	kingpin.Flag("multiple-ip-record", "Allow multiple IP addresses in A record").Default("true").BoolVar(&GoogleConfig.MultipleIPRecord)
+	kingpin.Flag("dns-ptr-records", "Manage PTR records of endpoint IPs in in-addr.arpa and ip6.arpa zones among dns-zones").BoolVar(&GoogleConfig.PTRRecords)
+	kingpin.Flag("dns-change-batch-size", "Maximum number of resource record sets in one DNS change").Default("100").IntVar(&GoogleConfig.ChangeBatchSize)
+	kingpin.Flag("policy", "Sync policy: sync, upsert-only (no deletions) or create-only (no deletions and modifications)").Default("sync").EnumVar(&GoogleConfig.Policy, "sync", "upsert-only", "create-only")
+	kingpin.Flag("max-deletions", "Maximum number of records deleted in a DNS zone by one synchronization, unlimited when 0").Default("0").IntVar(&GoogleConfig.MaxDeletions)
+	kingpin.Flag("max-deletions-percent", "Maximum percentage of owned records deleted in a DNS zone by one synchronization, unlimited when 0").Default("0").Float64Var(&GoogleConfig.MaxDeletionsPercent)
+	kingpin.Flag("force-deletions", "Apply deletions exceeding max-deletions or max-deletions-percent").BoolVar(&GoogleConfig.ForceDeletions)
 	kingpin.Flag("buddy-label-prefix", "Prefix used in TXT records").Default(DefaultBuddyLabelPrefix).StringVar(&GoogleConfig.BuddyLabelPrefix)
+	kingpin.Flag("owner-id", "Identifier of the buddy deployment embedded in TXT records, only records with the same owner ID are managed").StringVar(&GoogleConfig.OwnerID)
+	kingpin.Flag("adopt-legacy-labels", "Manage records with TXT labels without owner ID, when owner-id is set").BoolVar(&GoogleConfig.AdoptLegacyLabels)
+	kingpin.Flag("google-api-retries", "Maximum number of retries of google API calls failed with a transient error").Default("5").IntVar(&GoogleConfig.APIRetries)
+	kingpin.Flag("google-api-retry-initial-backoff", "Initial backoff in milliseconds between retries of google API calls").Default("500").IntVar(&GoogleConfig.APIRetryInitialBackoff)
+	kingpin.Flag("google-api-retry-max-backoff", "Maximum backoff in milliseconds between retries of google API calls").Default("30000").IntVar(&GoogleConfig.APIRetryMaxBackoff)
+	kingpin.Flag("google-watch-operations", "Synchronize when instances are inserted, deleted, started, stopped or their metadata, tags or labels are set").BoolVar(&GoogleConfig.WatchOperations)
+	kingpin.Flag("google-watch-interval", "Interval in seconds between polls of zone operations").Default("2").IntVar(&GoogleConfig.WatchInterval)
+	kingpin.Flag("hostname-template", "Go template of default hostnames e.g. {{.Name}}.{{.Zone}}, it can be overridden by hostname-template instance metadata").StringVar(&GoogleConfig.HostnameTemplate)
+	kingpin.Flag("google-key-precedence", "Comma separated instance key sources in the order of precedence: metadata, tags and labels").Default("metadata,tags,labels").StringVar(&GoogleConfig.KeyPrecedence)
+	kingpin.Flag("google-instance-statuses", "Comma separated statuses of instances with records, all statuses are allowed when empty").Default("RUNNING").StringVar(&GoogleConfig.InstanceStatuses)
+	kingpin.Flag("google-instance-status-grace-period", "Period in seconds an instance keeps its records after leaving the allowed statuses").Default("60").IntVar(&GoogleConfig.InstanceStatusGracePeriod)
+	kingpin.Flag("google-alias-hostname-suffix", "Suffix of default hostnames of alias IPs, which have no alias-ip-hostname").Default("-alias").StringVar(&GoogleConfig.AliasHostnameSuffix)
+}
+
+// GoogleComputeProjects returns projects scanned by the google producer
+func GoogleComputeProjects() []string {
+	projects := make([]string, 0, 1)
+	seen := make(map[string]struct{})
+	for _, project := range strings.Split(GoogleConfig.ComputeProjects, ",") {
+		project = strings.TrimSpace(project)
+		if _, ok := seen[project]; ok || project == "" {
+			continue
+		}
+		seen[project] = struct{}{}
+		projects = append(projects, project)
+	}
+	if len(projects) == 0 && GoogleConfig.Project != "" {
+		projects = append(projects, GoogleConfig.Project)
+	}
+	return projects
+}
+
+// GoogleProjectLabels returns true when several compute projects are scanned, so that TXT labels identify the compute
+// project of records. Labels of single compute project deployments are unchanged.
+func GoogleProjectLabels() bool {
+	return len(GoogleComputeProjects()) > 1
+}
+
+// GoogleDNSZones returns names of managed DNS zones: external-ip-dns-zone, internal-ip-dns-zone and dns-zones
+func GoogleDNSZones() map[string]struct{} {
+	result := make(map[string]struct{})
+
+	if GoogleConfig.ExternalIPDNSZone != "" {
+		result[GoogleConfig.ExternalIPDNSZone] = struct{}{}
+	}
+	if GoogleConfig.InternalIPDNSZone != "" {
+		result[GoogleConfig.InternalIPDNSZone] = struct{}{}
+	}
+	for _, zone := range strings.Split(GoogleConfig.DNSZones, ",") {
+		if zone != "" {
+			result[zone] = struct{}{}
+		}
+	}
+	return result
+}
+
+// GoogleDNSProject returns project of DNS managed zones synchronized by the google consumer
+func GoogleDNSProject() string {
+	if GoogleConfig.DNSProject != "" {
+		return GoogleConfig.DNSProject
+	}
+	return GoogleConfig.Project
+}
+
+// NewGoogleRetryPolicy creates RetryPolicy of google API calls from the configuration
+func NewGoogleRetryPolicy() *RetryPolicy {
+	return NewRetryPolicy(GoogleConfig.APIRetries,
+		time.Duration(GoogleConfig.APIRetryInitialBackoff)*time.Millisecond,
+		time.Duration(GoogleConfig.APIRetryMaxBackoff)*time.Millisecond)
 }
@@ -0,0 +1,34 @@
+package pkg
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGoogleProjects(t *testing.T) {
+	a := assert.New(t)
+	defer func(project, computeProjects, dnsProject string) {
+		GoogleConfig.Project, GoogleConfig.ComputeProjects, GoogleConfig.DNSProject = project, computeProjects, dnsProject
+	}(GoogleConfig.Project, GoogleConfig.ComputeProjects, GoogleConfig.DNSProject)
+
+	GoogleConfig.Project, GoogleConfig.ComputeProjects, GoogleConfig.DNSProject = "", "", ""
+	a.Empty(GoogleComputeProjects())
+	a.Empty(GoogleDNSProject())
+
+	GoogleConfig.Project = "my-project"
+	a.Equal([]string{"my-project"}, GoogleComputeProjects())
+	a.Equal("my-project", GoogleDNSProject())
+	a.False(GoogleProjectLabels())
+
+	GoogleConfig.ComputeProjects, GoogleConfig.DNSProject = "service-a, service-b,,service-a", "networking"
+	a.Equal([]string{"service-a", "service-b"}, GoogleComputeProjects())
+	a.Equal("networking", GoogleDNSProject())
+	a.True(GoogleProjectLabels())
+
+	// DNS project with a single compute project
+	GoogleConfig.ComputeProjects = "service-a"
+	a.False(GoogleProjectLabels())
+	GoogleConfig.ComputeProjects = ""
+	a.Equal("networking", GoogleDNSProject())
+	a.False(GoogleProjectLabels())
+}
@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	DefaultKubernetesClusterName = "kubernetes"
+)
+
+// KubernetesConfig provides configuration of kubernetes producer
+var KubernetesConfig struct {
+	Kubeconfig string
+	Namespace  string
+	// used instead of compute zone in TXT records
+	ClusterName string
+}
+
+func init() {
+	kingpin.Flag("kubeconfig", "Path to kubeconfig file, in-cluster configuration is used when empty").StringVar(&KubernetesConfig.Kubeconfig)
+	kingpin.Flag("kubernetes-namespace", "Namespace of the services to manage, all namespaces when empty").StringVar(&KubernetesConfig.Namespace)
+	kingpin.Flag("kubernetes-cluster-name", "Name of the kubernetes cluster used as compute zone in TXT records").Default(DefaultKubernetesClusterName).StringVar(&KubernetesConfig.ClusterName)
+}
@@ -0,0 +1,87 @@
+package pkg
+
+import (
+	"errors"
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/googleapi"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+var (
+	retriesCounter *prometheus.CounterVec
+)
+
+func init() {
+	retriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_api",
+		Name:      "retries",
+		Help:      "Number of retried google API calls.",
+	},
+		[]string{"operation"},
+	)
+	prometheus.MustRegister(retriesCounter)
+}
+
+// RetryPolicy retries google API calls failed with a transient error using exponential backoff with jitter
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	sleep          func(time.Duration)
+}
+
+// NewRetryPolicy creates new RetryPolicy
+func NewRetryPolicy(maxRetries int, initialBackoff time.Duration, maxBackoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{MaxRetries: maxRetries, InitialBackoff: initialBackoff, MaxBackoff: maxBackoff, sleep: time.Sleep}
+}
+
+// Do calls idempotent fn until it succeeds, fails with not retryable error or the retries are exhausted
+func (p *RetryPolicy) Do(operation string, fn func() error) error {
+	return p.do(operation, IsRetryable, fn)
+}
+
+// DoChange calls fn changing resources until it succeeds, fails with an error other than rate limit or the retries
+// are exhausted. A rate limited change is not applied, so it is safe to resend it.
+func (p *RetryPolicy) DoChange(operation string, fn func() error) error {
+	return p.do(operation, IsRateLimited, fn)
+}
+
+func (p *RetryPolicy) do(operation string, retryable func(error) bool, fn func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxRetries || !retryable(err) {
+			return err
+		}
+		// equal jitter: half of the backoff is fixed, the other half is random
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		log.Warnf("[Google API] %s failed, retry %d/%d in %v: %v", operation, attempt+1, p.MaxRetries, delay, err)
+		retriesCounter.WithLabelValues(operation).Inc()
+		p.sleep(delay)
+
+		backoff *= 2
+		if backoff > p.MaxBackoff {
+			backoff = p.MaxBackoff
+		}
+	}
+}
+
+// IsRetryable returns true for google API errors caused by rate limits (429), server errors (5xx)
+// and failed preconditions (412)
+func IsRetryable(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == http.StatusTooManyRequests || apiErr.Code == http.StatusPreconditionFailed || apiErr.Code >= http.StatusInternalServerError
+}
+
+// IsRateLimited returns true for google API errors caused by rate limits (429)
+func IsRateLimited(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusTooManyRequests
+}
@@ -0,0 +1,98 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	for _, tc := range []struct {
+		err       error
+		retryable bool
+	}{
+		{err: &googleapi.Error{Code: 429}, retryable: true},
+		{err: &googleapi.Error{Code: 500}, retryable: true},
+		{err: &googleapi.Error{Code: 503}, retryable: true},
+		{err: &googleapi.Error{Code: 412}, retryable: true},
+		{err: fmt.Errorf("wrapped: %w", &googleapi.Error{Code: 502}), retryable: true},
+		{err: &googleapi.Error{Code: 400}, retryable: false},
+		{err: &googleapi.Error{Code: 404}, retryable: false},
+		{err: &googleapi.Error{Code: 409}, retryable: false},
+		{err: errors.New("connection refused"), retryable: false},
+	} {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.Equal(t, tc.retryable, IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	a := assert.New(t)
+
+	var delays []time.Duration
+	p := NewRetryPolicy(3, 100*time.Millisecond, 300*time.Millisecond)
+	p.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	// succeeds after transient errors
+	calls := 0
+	err := p.Do("test", func() error {
+		calls++
+		if calls < 3 {
+			return &googleapi.Error{Code: 503}
+		}
+		return nil
+	})
+	a.NoError(err)
+	a.Equal(3, calls)
+	a.Len(delays, 2)
+	a.True(delays[0] >= 50*time.Millisecond && delays[0] <= 100*time.Millisecond, delays[0])
+	a.True(delays[1] >= 100*time.Millisecond && delays[1] <= 200*time.Millisecond, delays[1])
+
+	// retries are exhausted, backoff is limited by max backoff
+	calls = 0
+	delays = nil
+	err = p.Do("test", func() error {
+		calls++
+		return &googleapi.Error{Code: 429}
+	})
+	a.Error(err)
+	a.Equal(4, calls)
+	a.Len(delays, 3)
+	a.True(delays[2] >= 150*time.Millisecond && delays[2] <= 300*time.Millisecond, delays[2])
+
+	// not retryable error
+	calls = 0
+	err = p.Do("test", func() error {
+		calls++
+		return &googleapi.Error{Code: 404}
+	})
+	a.Error(err)
+	a.Equal(1, calls)
+
+	// changes are retried only when rate limited
+	for _, code := range []int{412, 503} {
+		calls = 0
+		err = p.DoChange("test", func() error {
+			calls++
+			return &googleapi.Error{Code: code}
+		})
+		a.Error(err)
+		a.Equal(1, calls)
+	}
+	calls = 0
+	err = p.DoChange("test", func() error {
+		calls++
+		if calls < 2 {
+			return &googleapi.Error{Code: 429}
+		}
+		return nil
+	})
+	a.NoError(err)
+	a.Equal(2, calls)
+}
@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+// RFC2136Config provides configuration of rfc2136 consumer
+var RFC2136Config struct {
+	// host:port of the DNS server
+	Server        string
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+}
+
+func init() {
+	kingpin.Flag("rfc2136-server", "Address host:port of the DNS server accepting zone transfers and dynamic updates").StringVar(&RFC2136Config.Server)
+	kingpin.Flag("rfc2136-tsig-keyname", "Name of the TSIG key, updates are not signed when empty").StringVar(&RFC2136Config.TSIGKeyName)
+	kingpin.Flag("rfc2136-tsig-secret", "Base64 encoded secret of the TSIG key").StringVar(&RFC2136Config.TSIGSecret)
+	kingpin.Flag("rfc2136-tsig-algorithm", "TSIG algorithm: hmac-md5, hmac-sha1, hmac-sha256 or hmac-sha512").Default("hmac-sha256").StringVar(&RFC2136Config.TSIGAlgorithm)
+}
@@ -3,11 +3,16 @@ package producers
 import (
 	log "github.com/Sirupsen/logrus"
 
+	"errors"
 	"fmt"
 	"github.com/everesio/buddy/pkg"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/api/compute/v1"
+	"net"
 	"net/http"
+	"path"
+	"strings"
+	"time"
 )
 
 var (
@@ -30,21 +35,39 @@ type googleInstance struct {
 	// googleInstance name. It must be 1-63 characters long, comply with RFC1035
 	// and match the regular expression [a-z]([-a-z0-9]*[a-z0-9])?
 	Name string `json:"name"`
-	// An IPv4 internal network address
-	InternalIP string `json:"internalIP"`
-	// An external IP address associated with this instance
-	ExternalIP string `json:"externalIP,omitempty"`
+	// Network interfaces in the order of the instance nicN indexes
+	NetworkInterfaces []googleNetworkInterface `json:"networkInterfaces"`
 	// Metadata key/value pairs
 	Metadata map[string]string `json:"metadata,omitempty"`
 	// Tags
 	Tags map[string]struct{} `json:"tags,omitempty"`
+	// Labels key/value pairs
+	Labels map[string]string `json:"labels,omitempty"`
 	// Name of google compute zone
 	ComputeZone string `json:"zone"`
+	// Status e.g. RUNNING or TERMINATED
+	Status string `json:"status"`
+	// Project ID of the instance
+	Project string `json:"project"`
+}
+
+type googleNetworkInterface struct {
+	// Index of the interface e.g. 1 for nic1
+	Index int `json:"index"`
+	// An IPv4 internal network address
+	InternalIP string `json:"internalIP"`
+	// An IPv6 internal network address
+	InternalIPv6 string `json:"internalIPv6,omitempty"`
+	// External IPv4 and IPv6 addresses of the interface access configs
+	ExternalIPs []string `json:"externalIPs,omitempty"`
+	// Single IP addresses of the interface alias IP ranges
+	AliasIPs []string `json:"aliasIPs,omitempty"`
 }
 
 type computeEngineService struct {
 	project string
 	service *compute.Service
+	retry   *pkg.RetryPolicy
 }
 
 func newComputeEngineService(project string, client *http.Client) (*computeEngineService, error) {
@@ -52,7 +75,7 @@ func newComputeEngineService(project string, client *http.Client) (*computeEngin
 	if err != nil {
 		return nil, err
 	}
-	return &computeEngineService{project: project, service: service}, nil
+	return &computeEngineService{project: project, service: service, retry: pkg.NewGoogleRetryPolicy()}, nil
 }
 
 func (svc *computeEngineService) getInstances(zone string) ([]googleInstance, error) {
@@ -69,30 +92,80 @@ func (svc *computeEngineService) getInstances(zone string) ([]googleInstance, er
 		if pageToken != "" {
 			req.PageToken(pageToken)
 		}
-		computeInstanceList, err := req.Do()
+		var computeInstanceList *compute.InstanceList
+		err := svc.retry.Do("compute_instances_list", func() (err error) {
+			computeInstanceList, err = req.Do()
+			return err
+		})
 		if err != nil {
 			return nil, fmt.Errorf("[Compute Engine] Unable to retrieve list of instances: %v", err)
 		}
-		for _, computeInstance := range computeInstanceList.Items {
-			instance, err := fromComputeInstance(computeInstance)
-			// computeInstance container zone URL
-			instance.ComputeZone = zone
-			if err != nil {
-				log.Warnln(err)
+		instances = append(instances, svc.fromComputeInstances(zone, computeInstanceList.Items)...)
+		if computeInstanceList.NextPageToken == "" {
+			break
+		}
+		pageToken = computeInstanceList.NextPageToken
+	}
+	return instances, nil
+}
+
+// getAllInstances retrieves instances of all project zones using the aggregated list. Zones without instances are also returned.
+func (svc *computeEngineService) getAllInstances() (map[string][]googleInstance, error) {
+	timer := pkg.NewTimer(prometheus.ObserverFunc(func(v float64) {
+		requestInstancesTimeSummary.WithLabelValues(allComputeZones).Observe(v)
+	}))
+	defer timer.ObserveDuration()
+
+	instances := make(map[string][]googleInstance)
+
+	pageToken := ""
+	for {
+		req := svc.service.Instances.AggregatedList(svc.project)
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+		var aggregatedList *compute.InstanceAggregatedList
+		err := svc.retry.Do("compute_instances_aggregated_list", func() (err error) {
+			aggregatedList, err = req.Do()
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[Compute Engine] Unable to retrieve aggregated list of instances: %v", err)
+		}
+		for scope, scopedList := range aggregatedList.Items {
+			// instances are listed in zones/<zone> scopes
+			if !strings.HasPrefix(scope, "zones/") {
 				continue
 			}
-			instances = append(instances, *instance)
+			zone := strings.TrimPrefix(scope, "zones/")
+			instances[zone] = append(instances[zone], svc.fromComputeInstances(zone, scopedList.Instances)...)
 		}
-		if computeInstanceList.NextPageToken == "" {
+		if aggregatedList.NextPageToken == "" {
 			break
 		}
-		pageToken = computeInstanceList.NextPageToken
+		pageToken = aggregatedList.NextPageToken
 	}
 	return instances, nil
 }
 
+func (svc *computeEngineService) fromComputeInstances(zone string, computeInstances []*compute.Instance) []googleInstance {
+	instances := make([]googleInstance, 0, len(computeInstances))
+	for _, computeInstance := range computeInstances {
+		instance, err := fromComputeInstance(computeInstance)
+		if err != nil {
+			log.Warnln(err)
+			continue
+		}
+		// computeInstance container zone URL
+		instance.ComputeZone = zone
+		instance.Project = svc.project
+		instances = append(instances, *instance)
+	}
+	return instances
+}
+
 func fromComputeInstance(computeInstance *compute.Instance) (*googleInstance, error) {
-	instance := &googleInstance{Name: computeInstance.Name, Metadata: make(map[string]string), Tags: map[string]struct{}{}}
+	instance := &googleInstance{Name: computeInstance.Name, Status: computeInstance.Status, Metadata: make(map[string]string), Tags: map[string]struct{}{}, Labels: make(map[string]string)}
 	for _, md := range computeInstance.Metadata.Items {
 		instance.Metadata[md.Key] = *md.Value
 	}
@@ -100,23 +173,184 @@ func fromComputeInstance(computeInstance *compute.Instance) (*googleInstance, er
 	for _, tag := range computeInstance.Tags.Items {
 		instance.Tags[tag] = struct{}{}
 	}
-	if len(computeInstance.NetworkInterfaces) != 1 {
-		return nil, fmt.Errorf("[Compute Engine] Skip instance '%s'. googleInstance must have one internal IP", computeInstance.Name)
-	}
-	networkInterface := computeInstance.NetworkInterfaces[0]
-	instance.InternalIP = networkInterface.NetworkIP
 
-	if len(networkInterface.AccessConfigs) == 1 {
-		instance.ExternalIP = networkInterface.AccessConfigs[0].NatIP
-	} else if len(networkInterface.AccessConfigs) > 1 {
-		return nil, fmt.Errorf("[Compute Engine] Skip instance '%s'. Multiple external IPs are not supported", computeInstance.Name)
+	for key, value := range computeInstance.Labels {
+		instance.Labels[key] = value
+	}
+	if len(computeInstance.NetworkInterfaces) == 0 {
+		return nil, fmt.Errorf("[Compute Engine] Skip instance '%s'. googleInstance must have an internal IP", computeInstance.Name)
+	}
+	for index, networkInterface := range computeInstance.NetworkInterfaces {
+		nic := googleNetworkInterface{Index: index, InternalIP: networkInterface.NetworkIP}
+		for _, accessConfig := range networkInterface.AccessConfigs {
+			if accessConfig.NatIP != "" {
+				nic.ExternalIPs = append(nic.ExternalIPs, accessConfig.NatIP)
+			}
+		}
+		for _, accessConfig := range networkInterface.Ipv6AccessConfigs {
+			if accessConfig.ExternalIpv6 != "" {
+				nic.ExternalIPs = append(nic.ExternalIPs, accessConfig.ExternalIpv6)
+			}
+		}
+		nic.InternalIPv6 = networkInterface.Ipv6Address
+		for _, aliasIPRange := range networkInterface.AliasIpRanges {
+			ip, err := aliasIP(aliasIPRange.IpCidrRange)
+			if err != nil {
+				log.Warnf("[Compute Engine] Skip alias IP range '%s' of instance '%s': %v", aliasIPRange.IpCidrRange, computeInstance.Name, err)
+				continue
+			}
+			nic.AliasIPs = append(nic.AliasIPs, ip)
+		}
+		instance.NetworkInterfaces = append(instance.NetworkInterfaces, nic)
 	}
 	return instance, nil
 }
 
+// aliasIP returns the address of single IP alias range e.g. 10.1.0.5 or 10.1.0.5/32
+func aliasIP(ipCidrRange string) (string, error) {
+	if !strings.Contains(ipCidrRange, "/") {
+		if net.ParseIP(ipCidrRange) == nil {
+			return "", errors.New("invalid IP address")
+		}
+		return ipCidrRange, nil
+	}
+	ip, ipNet, err := net.ParseCIDR(ipCidrRange)
+	if err != nil {
+		return "", err
+	}
+	if ones, bits := ipNet.Mask.Size(); ones != bits {
+		return "", errors.New("only single IP ranges are supported")
+	}
+	return ip.String(), nil
+}
+
+// getOperations retrieves operations of the zone inserted after since, the latest first
+func (svc *computeEngineService) getOperations(zone string, since time.Time) ([]*compute.Operation, error) {
+	operations := make([]*compute.Operation, 0, 16)
+	pageToken := ""
+	filter := fmt.Sprintf(`insertTime > "%s"`, since.UTC().Format(operationsTimeFormat))
+	for {
+		req := svc.service.ZoneOperations.List(svc.project, zone).Filter(filter).OrderBy("creationTimestamp desc")
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+		var operationList *compute.OperationList
+		err := svc.retry.Do("compute_zone_operations_list", func() (err error) {
+			operationList, err = req.Do()
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[Compute Engine] Unable to retrieve list of operations: %v", err)
+		}
+		for _, operation := range operationList.Items {
+			insertTime, err := time.Parse(time.RFC3339, operation.InsertTime)
+			if err != nil || !insertTime.After(since) {
+				return operations, nil
+			}
+			operations = append(operations, operation)
+		}
+		if operationList.NextPageToken == "" {
+			break
+		}
+		pageToken = operationList.NextPageToken
+	}
+	return operations, nil
+}
+
+// getForwardingRules retrieves forwarding rules of the region or global forwarding rules, when the region is global
+func (svc *computeEngineService) getForwardingRules(region string) ([]*compute.ForwardingRule, error) {
+	forwardingRules := make([]*compute.ForwardingRule, 0, 16)
+	pageToken := ""
+	for {
+		var forwardingRuleList *compute.ForwardingRuleList
+		err := svc.retry.Do("compute_forwarding_rules_list", func() (err error) {
+			if region == globalComputeZone {
+				req := svc.service.GlobalForwardingRules.List(svc.project)
+				if pageToken != "" {
+					req.PageToken(pageToken)
+				}
+				forwardingRuleList, err = req.Do()
+				return err
+			}
+			req := svc.service.ForwardingRules.List(svc.project, region)
+			if pageToken != "" {
+				req.PageToken(pageToken)
+			}
+			forwardingRuleList, err = req.Do()
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[Compute Engine] Unable to retrieve list of forwarding rules in %s: %v", region, err)
+		}
+		forwardingRules = append(forwardingRules, forwardingRuleList.Items...)
+		if forwardingRuleList.NextPageToken == "" {
+			break
+		}
+		pageToken = forwardingRuleList.NextPageToken
+	}
+	return forwardingRules, nil
+}
+
+// getAllForwardingRules retrieves regional forwarding rules of all project regions using the aggregated list.
+// Regions without forwarding rules are also returned.
+func (svc *computeEngineService) getAllForwardingRules() (map[string][]*compute.ForwardingRule, error) {
+	forwardingRules := make(map[string][]*compute.ForwardingRule)
+	pageToken := ""
+	for {
+		req := svc.service.ForwardingRules.AggregatedList(svc.project)
+		if pageToken != "" {
+			req.PageToken(pageToken)
+		}
+		var aggregatedList *compute.ForwardingRuleAggregatedList
+		err := svc.retry.Do("compute_forwarding_rules_aggregated_list", func() (err error) {
+			aggregatedList, err = req.Do()
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("[Compute Engine] Unable to retrieve aggregated list of forwarding rules: %v", err)
+		}
+		for scope, scopedList := range aggregatedList.Items {
+			// regional forwarding rules are listed in regions/<region> scopes
+			if !strings.HasPrefix(scope, "regions/") {
+				continue
+			}
+			region := strings.TrimPrefix(scope, "regions/")
+			forwardingRules[region] = append(forwardingRules[region], scopedList.ForwardingRules...)
+		}
+		if aggregatedList.NextPageToken == "" {
+			break
+		}
+		pageToken = aggregatedList.NextPageToken
+	}
+	return forwardingRules, nil
+}
+
+// getAllZones retrieves names of all zones of the project and their regions
+func (svc *computeEngineService) getAllZones() ([]string, []string, error) {
+	var computeZones *compute.ZoneList
+	err := svc.retry.Do("compute_zones_list", func() (err error) {
+		computeZones, err = svc.service.Zones.List(svc.project).Do()
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("[Compute Engine] Unable to retrieve zones: %v", err)
+	}
+	zones := make([]string, 0, len(computeZones.Items))
+	regions := make([]string, 0, len(computeZones.Items))
+	for _, computeZone := range computeZones.Items {
+		zones = append(zones, computeZone.Name)
+		regions = append(regions, path.Base(computeZone.Region))
+	}
+	return union(zones, nil), union(regions, nil), nil
+}
+
 // GetZones retrieves zone names for a given region
 func (svc *computeEngineService) getZones(region string) ([]string, error) {
-	computeRegion, err := svc.service.Regions.Get(svc.project, region).Do()
+	var computeRegion *compute.Region
+	err := svc.retry.Do("compute_regions_get", func() (err error) {
+		computeRegion, err = svc.service.Regions.Get(svc.project, region).Do()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("[Compute Engine] Unable to retrieve region: %v", err)
 	}
@@ -124,7 +358,11 @@ func (svc *computeEngineService) getZones(region string) ([]string, error) {
 	for _, computeZoneURL := range computeRegion.Zones {
 		zonesURLs[computeZoneURL] = struct{}{}
 	}
-	computeZones, err := svc.service.Zones.List(svc.project).Do()
+	var computeZones *compute.ZoneList
+	err = svc.retry.Do("compute_zones_list", func() (err error) {
+		computeZones, err = svc.service.Zones.List(svc.project).Do()
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("[Compute Engine] Unable to retrieve zones: %v", err)
 	}
@@ -139,12 +377,19 @@ func (svc *computeEngineService) getZones(region string) ([]string, error) {
 
 // GetRegion retrieves the zone name names for a given zone
 func (svc *computeEngineService) getRegion(zone string) (string, error) {
-	req := svc.service.Zones.Get(svc.project, zone)
-	computeZone, err := req.Do()
+	var computeZone *compute.Zone
+	err := svc.retry.Do("compute_zones_get", func() (err error) {
+		computeZone, err = svc.service.Zones.Get(svc.project, zone).Do()
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("[Compute Engine] Unable to retrieve zone: %v", err)
 	}
-	computeRegions, err := svc.service.Regions.List(svc.project).Do()
+	var computeRegions *compute.RegionList
+	err = svc.retry.Do("compute_regions_list", func() (err error) {
+		computeRegions, err = svc.service.Regions.List(svc.project).Do()
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("[Compute Engine] Unable to retrieve regions: %v", err)
 	}
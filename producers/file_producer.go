@@ -0,0 +1,193 @@
+package producers
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+	"io/ioutil"
+	"os"
+	"sigs.k8s.io/yaml"
+	"sync"
+	"time"
+)
+
+var (
+	fileEndpointsGauge      prometheus.Gauge
+	fileReloadErrorsCounter prometheus.Counter
+)
+
+func init() {
+	fileEndpointsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "file_producer",
+		Name:      "endpoints",
+		Help:      "Number of valid endpoints read from the file.",
+	})
+	prometheus.MustRegister(fileEndpointsGauge)
+
+	fileReloadErrorsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "file_producer",
+		Name:      "reload_errors",
+		Help:      "Number of errors reloading the file.",
+	})
+	prometheus.MustRegister(fileReloadErrorsCounter)
+}
+
+// endpointsFile is the content of YAML or JSON file
+type endpointsFile struct {
+	ComputeZones []string        `json:"computeZones"`
+	Endpoints    []*pkg.Endpoint `json:"endpoints"`
+}
+
+// FileProducer reads endpoints from a declarative file
+type FileProducer struct {
+	sync.RWMutex
+	path string
+	// managed DNS zones, endpoints of other DNS zones are skipped
+	dnsZones map[string]struct{}
+	// the file is not watched when zero
+	watchInterval time.Duration
+	modTime       time.Time
+	size          int64
+	computeZones  []string
+	endpoints     []*pkg.Endpoint
+}
+
+// NewFileProducer creates new FileProducer
+func NewFileProducer() (*FileProducer, error) {
+	if pkg.FileConfig.Path == "" {
+		return nil, errors.New("Please provide --endpoints-file")
+	}
+	fp, err := newFileProducer(pkg.FileConfig.Path, pkg.GoogleDNSZones())
+	if err != nil {
+		return nil, err
+	}
+	if pkg.FileConfig.WatchInterval > 0 {
+		fp.watchInterval = time.Duration(pkg.FileConfig.WatchInterval) * time.Second
+	} else {
+		log.Warn("[File] Watching of the endpoints file is disabled.")
+	}
+	log.Printf("[File] File producer: file %s, compute zones %v", fp.path, fp.computeZones)
+	return fp, nil
+}
+
+func newFileProducer(path string, dnsZones map[string]struct{}) (*FileProducer, error) {
+	fp := &FileProducer{path: path, dnsZones: dnsZones}
+	if _, err := fp.reload(); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
+
+// Watch reloads the file when its modification time or size changes and signals changes, when it was reloaded.
+// It returns immediately when watching of the file is disabled.
+func (fp *FileProducer) Watch(changes chan<- struct{}, stop <-chan struct{}) {
+	if fp.watchInterval <= 0 {
+		return
+	}
+	for {
+		select {
+		case <-time.After(fp.watchInterval):
+		case <-stop:
+			return
+		}
+		reloaded, err := fp.reload()
+		if err != nil {
+			fileReloadErrorsCounter.Inc()
+			log.Errorf("%v. Keeping previous endpoints.", err)
+			continue
+		}
+		if reloaded {
+			log.Infof("[File] Reloaded %s: compute zones %v, %d endpoints", fp.path, fp.ComputeZones(), len(fp.currentEndpoints()))
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// reload reads the file if it has been changed since the last successful read
+func (fp *FileProducer) reload() (bool, error) {
+	info, err := os.Stat(fp.path)
+	if err != nil {
+		return false, fmt.Errorf("[File] Unable to stat %s: %v", fp.path, err)
+	}
+	fp.RLock()
+	unchanged := info.ModTime().Equal(fp.modTime) && info.Size() == fp.size
+	fp.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	data, err := ioutil.ReadFile(fp.path)
+	if err != nil {
+		return false, fmt.Errorf("[File] Unable to read %s: %v", fp.path, err)
+	}
+	var content endpointsFile
+	if err = yaml.UnmarshalStrict(data, &content); err != nil {
+		return false, fmt.Errorf("[File] Unable to parse %s: %v", fp.path, err)
+	}
+	if len(content.ComputeZones) == 0 {
+		return false, fmt.Errorf("[File] No compute zones in %s", fp.path)
+	}
+	endpoints := validEndpoints(content.ComputeZones, fp.dnsZones, content.Endpoints)
+
+	fp.Lock()
+	defer fp.Unlock()
+	fp.modTime = info.ModTime()
+	fp.size = info.Size()
+	fp.computeZones = content.ComputeZones
+	fp.endpoints = endpoints
+	fileEndpointsGauge.Set(float64(len(endpoints)))
+	return true, nil
+}
+
+// validEndpoints skips endpoints which would not be accepted by the consumer
+func validEndpoints(computeZones []string, dnsZones map[string]struct{}, endpoints []*pkg.Endpoint) []*pkg.Endpoint {
+	computeZonesMap := make(map[string]struct{})
+	for _, v := range computeZones {
+		computeZonesMap[v] = struct{}{}
+	}
+	result := make([]*pkg.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint == nil {
+			continue
+		}
+		if err := endpoint.Validate(); err != nil {
+			log.Warningf("[File] Skip invalid endpoint %v: %v", endpoint, err)
+			continue
+		}
+		if _, ok := computeZonesMap[endpoint.ComputeZone]; !ok {
+			log.Warningf("[File] Skip endpoint %v: compute zone is not one of %v", endpoint, computeZones)
+			continue
+		}
+		if _, ok := dnsZones[endpoint.DNSZone]; !ok {
+			log.Warningf("[File] Skip endpoint %v: DNS zone is not managed", endpoint)
+			continue
+		}
+		result = append(result, endpoint)
+	}
+	return result
+}
+
+func (fp *FileProducer) currentEndpoints() []*pkg.Endpoint {
+	fp.RLock()
+	defer fp.RUnlock()
+	return fp.endpoints
+}
+
+// Endpoints provides endpoints read from the file.
+func (fp *FileProducer) Endpoints() ([]*pkg.Endpoint, error) {
+	return fp.currentEndpoints(), nil
+}
+
+// ComputeZones provides compute zones read from the file
+func (fp *FileProducer) ComputeZones() []string {
+	fp.RLock()
+	defer fp.RUnlock()
+	return fp.computeZones
+}
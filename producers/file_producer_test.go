@@ -0,0 +1,162 @@
+package producers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var dnsZones = map[string]struct{}{"internal-example-com": {}}
+
+func writeEndpointsFile(t *testing.T, path string, content string) {
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileProducerYAML(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeEndpointsFile(t, path, `
+computeZones:
+- on-prem-1
+endpoints:
+- hostname: db
+  dnsZone: internal-example-com
+  ip: 192.168.0.1
+  computeZone: on-prem-1
+# invalid IP
+- hostname: web
+  dnsZone: internal-example-com
+  ip: 192.168.0.256
+  computeZone: on-prem-1
+# missing hostname
+- dnsZone: internal-example-com
+  ip: 192.168.0.2
+  computeZone: on-prem-1
+# compute zone is not managed
+- hostname: web
+  dnsZone: internal-example-com
+  ip: 192.168.0.3
+  computeZone: on-prem-2
+# DNS zone is not managed
+- hostname: web
+  dnsZone: external-example-com
+  ip: 192.168.0.4
+  computeZone: on-prem-1
+`)
+	fp, err := newFileProducer(path, dnsZones)
+	a.NoError(err)
+	a.Equal([]string{"on-prem-1"}, fp.ComputeZones())
+
+	endpoints, err := fp.Endpoints()
+	a.NoError(err)
+	a.Equal([]*pkg.Endpoint{{Hostname: "db", DNSZone: "internal-example-com", IP: "192.168.0.1", ComputeZone: "on-prem-1"}}, endpoints)
+}
+
+func TestFileProducerJSON(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "endpoints.json")
+	writeEndpointsFile(t, path, `{"computeZones": ["on-prem-1", "on-prem-2"], "endpoints": [
+		{"hostname": "db", "dnsZone": "internal-example-com", "ip": "192.168.0.1", "computeZone": "on-prem-1"},
+		{"hostname": "db", "dnsZone": "internal-example-com", "ip": "192.168.1.1", "computeZone": "on-prem-2"}
+	]}`)
+	fp, err := newFileProducer(path, dnsZones)
+	a.NoError(err)
+	a.Equal([]string{"on-prem-1", "on-prem-2"}, fp.ComputeZones())
+
+	endpoints, err := fp.Endpoints()
+	a.NoError(err)
+	a.Len(endpoints, 2)
+}
+
+func TestFileProducerInvalidFile(t *testing.T) {
+	a := assert.New(t)
+
+	dir := t.TempDir()
+	_, err := newFileProducer(filepath.Join(dir, "missing.yaml"), dnsZones)
+	a.Error(err)
+
+	path := filepath.Join(dir, "endpoints.yaml")
+	writeEndpointsFile(t, path, "computeZones: [on-prem-1]\nendpoint: []\n")
+	_, err = newFileProducer(path, dnsZones)
+	a.Error(err, "unknown field")
+
+	writeEndpointsFile(t, path, "endpoints: []\n")
+	_, err = newFileProducer(path, dnsZones)
+	a.Error(err, "no compute zones")
+}
+
+func TestFileProducerReload(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeEndpointsFile(t, path, "computeZones: [on-prem-1]\n")
+	fp, err := newFileProducer(path, dnsZones)
+	a.NoError(err)
+	a.Empty(fp.currentEndpoints())
+
+	reloaded, err := fp.reload()
+	a.NoError(err)
+	a.False(reloaded)
+
+	writeEndpointsFile(t, path, `
+computeZones: [on-prem-1]
+endpoints:
+- {hostname: db, dnsZone: internal-example-com, ip: 192.168.0.1, computeZone: on-prem-1}
+`)
+	reloaded, err = fp.reload()
+	a.NoError(err)
+	a.True(reloaded)
+	a.Len(fp.currentEndpoints(), 1)
+
+	// previous endpoints are kept
+	writeEndpointsFile(t, path, "computeZones: [on-prem-1\n")
+	_, err = fp.reload()
+	a.Error(err)
+	a.Len(fp.currentEndpoints(), 1)
+
+	os.Remove(path)
+	_, err = fp.reload()
+	a.Error(err)
+	a.Len(fp.currentEndpoints(), 1)
+}
+
+func TestFileProducerWatch(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	writeEndpointsFile(t, path, "computeZones: [on-prem-1]\n")
+	fp, err := newFileProducer(path, dnsZones)
+	a.NoError(err)
+	fp.watchInterval = 10 * time.Millisecond
+
+	changes := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		fp.Watch(changes, stop)
+		close(stopped)
+	}()
+
+	writeEndpointsFile(t, path, `
+computeZones: [on-prem-1]
+endpoints:
+- {hostname: db, dnsZone: internal-example-com, ip: 192.168.0.1, computeZone: on-prem-1}
+`)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reload was not signaled")
+	}
+	a.Len(fp.currentEndpoints(), 1)
+
+	close(stop)
+	<-stopped
+}
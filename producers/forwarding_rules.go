@@ -0,0 +1,165 @@
+package producers
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/compute/v1"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	// compute zone of global forwarding rules endpoints
+	globalComputeZone = "global"
+)
+
+var (
+	forwardingRuleEndpointsGauge *prometheus.GaugeVec
+)
+
+func init() {
+	forwardingRuleEndpointsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "google_producer",
+		Name:      "forwarding_rule_endpoints",
+		Help:      "Number of forwarding rules with internal or external IP.",
+	},
+		[]string{"project", "region"},
+	)
+	prometheus.MustRegister(forwardingRuleEndpointsGauge)
+}
+
+// regionForwardingRules are forwarding rules of a region or global forwarding rules in the project
+type regionForwardingRules struct {
+	project         string
+	region          string
+	forwardingRules []*compute.ForwardingRule
+}
+
+// fromForwardingRule converts the forwarding rule to an instance with a single network interface, so that it has the
+// same keys as instances. Description key=value pairs are metadata and labels are labels e.g.
+// description "internal-ip-hostname=api, internal-ip-dns-zone=internal-example-com".
+// IP address of internal load balancing schemes is an internal IP, otherwise it is an external IP.
+func fromForwardingRule(project string, region string, forwardingRule *compute.ForwardingRule) *googleInstance {
+	instance := &googleInstance{
+		Name:        forwardingRule.Name,
+		ComputeZone: region,
+		Project:     project,
+		Metadata:    parseDescription(forwardingRule.Description),
+		Tags:        map[string]struct{}{},
+		Labels:      forwardingRule.Labels,
+	}
+	nic := googleNetworkInterface{}
+	if strings.HasPrefix(forwardingRule.LoadBalancingScheme, "INTERNAL") {
+		nic.InternalIP = forwardingRule.IPAddress
+	} else {
+		nic.ExternalIPs = []string{forwardingRule.IPAddress}
+	}
+	instance.NetworkInterfaces = []googleNetworkInterface{nic}
+	return instance
+}
+
+// parseDescription returns key=value pairs separated by commas or white spaces. Words without '=' have empty value.
+func parseDescription(description string) map[string]string {
+	result := make(map[string]string)
+	fields := strings.FieldsFunc(description, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, field := range fields {
+		keyValue := strings.SplitN(field, "=", 2)
+		if len(keyValue) == 1 {
+			result[keyValue[0]] = ""
+		} else {
+			result[keyValue[0]] = keyValue[1]
+		}
+	}
+	return result
+}
+
+// forwardingRuleEndpoints returns internal and external endpoints of the forwarding rule, the default hostname is the rule name
+func (gp *GoogleProducer) forwardingRuleEndpoints(instance *googleInstance) ([]*pkg.Endpoint, []*pkg.Endpoint) {
+	nic := &instance.NetworkInterfaces[0]
+	for _, ip := range append([]string{nic.InternalIP}, nic.ExternalIPs...) {
+		if ip != "" && net.ParseIP(ip) == nil {
+			log.Warnf("[Compute Engine] Skip forwarding rule %s with invalid IP address '%s'", instance.Name, ip)
+			return nil, nil
+		}
+	}
+	var internalIPs []string
+	if nic.InternalIP != "" {
+		internalIPs = append(internalIPs, nic.InternalIP)
+	}
+	defaultName := func() (string, error) { return instance.Name, nil }
+	internalEndpoints := gp.newEndpoints(instance, nic.Index, defaultName, keyInternalIPHostname, keyInternalIPDNSZone, gp.internalIPDNSZone, internalIPs)
+	externalEndpoints := gp.newEndpoints(instance, nic.Index, defaultName, keyExternalIPHostname, keyExternalIPDNSZone, gp.externalIPDNSZone, nic.ExternalIPs)
+	ttl := gp.dnsTTL(instance, nic.Index)
+	setTTL(internalEndpoints, ttl)
+	setTTL(externalEndpoints, ttl)
+	return internalEndpoints, externalEndpoints
+}
+
+// listForwardingRules lists regional forwarding rules of managed regions and global forwarding rules. In all zones mode,
+// the aggregated list is used and regions are updated with regions of the projects.
+func (gp *GoogleProducer) listForwardingRules() ([]regionForwardingRules, error) {
+	result := make([]regionForwardingRules, 0, 4)
+	for _, computeEngineService := range gp.computeEngineServices {
+		if gp.allZones {
+			forwardingRulesByRegion, err := computeEngineService.getAllForwardingRules()
+			if err != nil {
+				return nil, err
+			}
+			regions := make([]string, 0, len(forwardingRulesByRegion))
+			for region := range forwardingRulesByRegion {
+				regions = append(regions, region)
+			}
+			sort.Strings(regions)
+			for _, region := range regions {
+				result = append(result, regionForwardingRules{project: computeEngineService.project, region: region, forwardingRules: forwardingRulesByRegion[region]})
+			}
+			gp.addRegions(regions)
+		} else {
+			for _, region := range gp.regions {
+				forwardingRules, err := computeEngineService.getForwardingRules(region)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, regionForwardingRules{project: computeEngineService.project, region: region, forwardingRules: forwardingRules})
+			}
+		}
+		forwardingRules, err := computeEngineService.getForwardingRules(globalComputeZone)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, regionForwardingRules{project: computeEngineService.project, region: globalComputeZone, forwardingRules: forwardingRules})
+	}
+	return result, nil
+}
+
+// forwardingRulesEndpoints provides endpoints of regional and global forwarding rules
+func (gp *GoogleProducer) forwardingRulesEndpoints() ([]*pkg.Endpoint, error) {
+	regionForwardingRules, err := gp.listForwardingRules()
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make([]*pkg.Endpoint, 0, 16)
+	for _, v := range regionForwardingRules {
+		var count int
+		for _, forwardingRule := range v.forwardingRules {
+			internal, external := gp.forwardingRuleEndpoints(fromForwardingRule(v.project, v.region, forwardingRule))
+			endpoints = append(endpoints, internal...)
+			endpoints = append(endpoints, external...)
+			count += len(internal) + len(external)
+		}
+		forwardingRuleEndpointsGauge.WithLabelValues(v.project, v.region).Set(float64(count))
+	}
+	return endpoints, nil
+}
+
+// addRegions adds regions to managed regions. Regions are never removed, so records of removed forwarding rules are deleted.
+func (gp *GoogleProducer) addRegions(regions []string) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	gp.regions = union(gp.regions, regions)
+}
@@ -0,0 +1,63 @@
+package producers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"testing"
+)
+
+func TestForwardingRuleEndpoints(t *testing.T) {
+	gp := &GoogleProducer{internalIPDNSZone: "internal-zone", externalIPDNSZone: "external-zone"}
+	for _, tc := range []struct {
+		name           string
+		forwardingRule *compute.ForwardingRule
+		endpoints      []*pkg.Endpoint
+	}{
+		{
+			name:           "no keys",
+			forwardingRule: &compute.ForwardingRule{Name: "api-ilb", IPAddress: "10.0.0.10", LoadBalancingScheme: "INTERNAL", Description: "API load balancer"},
+		},
+		{
+			name:           "internal description keys",
+			forwardingRule: &compute.ForwardingRule{Name: "api-ilb", IPAddress: "10.0.0.10", LoadBalancingScheme: "INTERNAL_MANAGED", Description: "internal-ip-hostname=api, internal-ip-dns-zone=other-zone"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "api", DNSZone: "other-zone", IP: "10.0.0.10", ComputeZone: "europe-west1", Project: "my-project"},
+			},
+		},
+		{
+			name:           "external labels with default hostname",
+			forwardingRule: &compute.ForwardingRule{Name: "web-lb", IPAddress: "35.0.0.10", LoadBalancingScheme: "EXTERNAL", Labels: map[string]string{keyExternalIPDNSZone: ""}},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "web-lb", DNSZone: "external-zone", IP: "35.0.0.10", ComputeZone: "europe-west1", Project: "my-project"},
+			},
+		},
+		{
+			name:           "internal keys of external load balancer",
+			forwardingRule: &compute.ForwardingRule{Name: "web-lb", IPAddress: "35.0.0.10", LoadBalancingScheme: "EXTERNAL", Labels: map[string]string{keyInternalIPDNSZone: ""}},
+		},
+		{
+			name:           "invalid IP",
+			forwardingRule: &compute.ForwardingRule{Name: "web-lb", IPAddress: "invalid", Labels: map[string]string{keyExternalIPDNSZone: ""}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			internal, external := gp.forwardingRuleEndpoints(fromForwardingRule("my-project", "europe-west1", tc.forwardingRule))
+			assert.Equal(t, tc.endpoints, append(internal, external...))
+		})
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	assert.Equal(t, map[string]string{"internal-ip-hostname": "api.prod", "internal-ip-dns-zone": "", "LB": ""},
+		parseDescription("LB internal-ip-hostname=api.prod,internal-ip-dns-zone"))
+}
+
+func TestForwardingRulesComputeZones(t *testing.T) {
+	gp := &GoogleProducer{computeZones: []string{"europe-west1-b", "europe-west1-c"}, regions: []string{"europe-west1"}}
+	assert.Equal(t, []string{"europe-west1-b", "europe-west1-c"}, gp.ComputeZones())
+
+	gp.forwardingRules = true
+	assert.Equal(t, []string{"europe-west1-b", "europe-west1-c", "europe-west1", "global"}, gp.ComputeZones())
+	assert.Equal(t, []string{"europe-west1-b", "europe-west1-c"}, gp.zones())
+}
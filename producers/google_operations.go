@@ -0,0 +1,146 @@
+package producers
+
+import (
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/compute/v1"
+	"strings"
+	"time"
+)
+
+const (
+	// format of the insert time in operations filter, operations list their times with milliseconds
+	operationsTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+var (
+	// operations which change instance IPs, status, metadata, tags or labels
+	watchedOperationTypes = map[string]struct{}{
+		"insert":      {},
+		"delete":      {},
+		"start":       {},
+		"stop":        {},
+		"suspend":     {},
+		"resume":      {},
+		"setMetadata": {},
+		"setTags":     {},
+		"setLabels":   {},
+	}
+
+	instanceOperationsCounter *prometheus.CounterVec
+)
+
+func init() {
+	instanceOperationsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "buddy",
+		Subsystem: "google_producer",
+		Name:      "instance_operations",
+		Help:      "Number of observed instance operations which trigger synchronization.",
+	},
+		[]string{"project", "compute_zone", "operation_type"},
+	)
+	prometheus.MustRegister(instanceOperationsCounter)
+}
+
+// Watch polls zone operations and signals changes, when instances were inserted, deleted, started, stopped or their metadata, tags or labels were set.
+// It returns immediately when watching of operations is disabled.
+func (gp *GoogleProducer) Watch(changes chan<- struct{}, stop <-chan struct{}) {
+	if gp.watchInterval <= 0 {
+		return
+	}
+	log.Infof("[Compute Engine] Watching operations every %v", gp.watchInterval)
+	watermarks := make(map[string]*operationsWatermark)
+	for {
+		if gp.pollOperations(watermarks) {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		}
+		select {
+		case <-time.After(gp.watchInterval):
+		case <-stop:
+			return
+		}
+	}
+}
+
+// operationsWatermark tracks the operations of a project zone seen by previous polls
+type operationsWatermark struct {
+	// operations inserted at or before are not listed again
+	insertTime time.Time
+	// operations finished at or before were already signaled
+	endTime time.Time
+}
+
+// pollOperations returns true when an instance operation finished after the last seen operation of the project zone.
+// Operations finished before the first poll of the project zone are ignored.
+func (gp *GoogleProducer) pollOperations(watermarks map[string]*operationsWatermark) bool {
+	changed := false
+	for _, computeEngineService := range gp.computeEngineServices {
+		for _, zone := range gp.zones() {
+			key := computeEngineService.project + "/" + zone
+			watermark, ok := watermarks[key]
+			if !ok {
+				now := time.Now()
+				watermark = &operationsWatermark{insertTime: now, endTime: now}
+				watermarks[key] = watermark
+			}
+			operations, err := computeEngineService.getOperations(zone, watermark.insertTime)
+			if err != nil {
+				log.Warnf("[Compute Engine] Watch operations: %v", err)
+				continue
+			}
+			finished := instanceOperations(operations, watermark)
+			for _, operation := range finished {
+				log.Debugf("[Compute Engine] Operation %s %s finished at %s", operation.OperationType, operation.TargetLink, operation.EndTime)
+				instanceOperationsCounter.WithLabelValues(computeEngineService.project, zone, operation.OperationType).Inc()
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// instanceOperations returns watched instance operations finished after the end time of the watermark and advances
+// the watermark. The insert time of the watermark stays before operations which are still running, so that they are
+// listed again until they finish.
+func instanceOperations(operations []*compute.Operation, watermark *operationsWatermark) []*compute.Operation {
+	var result []*compute.Operation
+	latestInsertTime := watermark.insertTime
+	var runningInsertTime time.Time
+	latestEndTime := watermark.endTime
+	for _, operation := range operations {
+		insertTime, err := time.Parse(time.RFC3339, operation.InsertTime)
+		if err != nil {
+			continue
+		}
+		if insertTime.After(latestInsertTime) {
+			latestInsertTime = insertTime
+		}
+		if operation.Status != "DONE" {
+			if runningInsertTime.IsZero() || insertTime.Before(runningInsertTime) {
+				runningInsertTime = insertTime
+			}
+			continue
+		}
+		endTime, err := time.Parse(time.RFC3339, operation.EndTime)
+		if err != nil || !endTime.After(watermark.endTime) {
+			continue
+		}
+		if endTime.After(latestEndTime) {
+			latestEndTime = endTime
+		}
+		if _, ok := watchedOperationTypes[operation.OperationType]; !ok || !strings.Contains(operation.TargetLink, "/instances/") {
+			continue
+		}
+		result = append(result, operation)
+	}
+	watermark.insertTime = latestInsertTime
+	if !runningInsertTime.IsZero() {
+		// the filter excludes the insert time, running operations must be listed again
+		watermark.insertTime = runningInsertTime.Add(-time.Millisecond)
+	}
+	watermark.endTime = latestEndTime
+	return result
+}
@@ -1,6 +1,7 @@
 package producers
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
@@ -9,6 +10,11 @@ import (
 	"golang.org/x/net/context"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/compute/v1"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 )
 
 const (
@@ -16,9 +22,23 @@ const (
 	keyExternalIPDNSZone  = "external-ip-dns-zone"
 	keyInternalIPHostname = "internal-ip-hostname"
 	keyExternalIPHostname = "external-ip-hostname"
+	keyAliasIPDNSZone     = "alias-ip-dns-zone"
+	keyAliasIPHostname    = "alias-ip-hostname"
+	keyInternalIPAliases  = "internal-ip-aliases"
+	keyExternalIPAliases  = "external-ip-aliases"
+	keyInternalIPSRV      = "internal-ip-srv"
+	keyExternalIPSRV      = "external-ip-srv"
+	keyDNSTTL             = "dns-ttl"
+
+	keySourceMetadata = "metadata"
+	keySourceTags     = "tags"
+	keySourceLabels   = "labels"
 )
 
 var (
+	// metadata and tags take precedence over labels by default
+	defaultKeySources = []string{keySourceMetadata, keySourceTags, keySourceLabels}
+
 	internalEndpointsGauge *prometheus.GaugeVec
 	externalEndpointsGauge *prometheus.GaugeVec
 )
@@ -30,7 +50,7 @@ func init() {
 		Name:      "internal_endpoints",
 		Help:      "Number of instances with internal IP.",
 	},
-		[]string{"compute_zone"},
+		[]string{"project", "compute_zone"},
 	)
 	prometheus.MustRegister(internalEndpointsGauge)
 
@@ -40,23 +60,45 @@ func init() {
 		Name:      "external_endpoints",
 		Help:      "Number of instances with external IP.",
 	},
-		[]string{"compute_zone"},
+		[]string{"project", "compute_zone"},
 	)
 	prometheus.MustRegister(externalEndpointsGauge)
 }
 
 // GoogleProducer reads data from compute engine
 type GoogleProducer struct {
-	computeZones         []string
-	externalIPDNSZone    string
-	internalIPDNSZone    string
-	computeEngineService *computeEngineService
+	// guards computeZones and regions, which are updated in all zones mode
+	mu           sync.Mutex
+	computeZones []string
+	// regions of managed compute zones
+	regions []string
+	// forwarding rules of regions and global forwarding rules are listed
+	forwardingRules   bool
+	externalIPDNSZone string
+	internalIPDNSZone string
+	// one service per compute project
+	computeEngineServices []*computeEngineService
+	// all zones of the projects are managed using the aggregated list
+	allZones bool
+	// maximum number of compute zones listed in parallel
+	zoneConcurrency int
+	// zone operations are not watched when zero
+	watchInterval time.Duration
+	// default hostname template, it can be overridden by instance metadata
+	hostnameTemplate *template.Template
+	// instance metadata, tags and labels in the order of precedence, defaultKeySources when empty
+	keySources []string
+	// instances with all statuses are allowed when nil
+	statusFilter *statusFilter
+	// added to default hostnames of alias IPs
+	aliasHostnameSuffix string
 }
 
 // NewGoogleProducer creates new GoogleProducer
 func NewGoogleProducer() (*GoogleProducer, error) {
-	if pkg.GoogleConfig.Project == "" {
-		return nil, errors.New("Please provide --google-project")
+	projects := pkg.GoogleComputeProjects()
+	if len(projects) == 0 {
+		return nil, errors.New("Please provide --google-project or --google-compute-projects")
 	}
 
 	client, err := google.DefaultClient(context.Background(), compute.ComputeReadonlyScope)
@@ -64,13 +106,18 @@ func NewGoogleProducer() (*GoogleProducer, error) {
 		return nil, fmt.Errorf("[Compute Engine] Unable to create google oauth2 http client %v", err)
 	}
 
-	computeEngineService, err := newComputeEngineService(pkg.GoogleConfig.Project, client)
-	if err != nil {
-		return nil, fmt.Errorf("[Compute Engine] Unable to create compute engine service: %v", err)
+	computeEngineServices := make([]*computeEngineService, 0, len(projects))
+	for _, project := range projects {
+		computeEngineService, err := newComputeEngineService(project, client)
+		if err != nil {
+			return nil, fmt.Errorf("[Compute Engine] Unable to create compute engine service: %v", err)
+		}
+		computeEngineServices = append(computeEngineServices, computeEngineService)
 	}
 
-	var computeZones []string
-	if computeZones, err = getComputeZones(computeEngineService); err != nil {
+	// compute zone names are the same in all projects, zones of instances listed in all zones mode are added
+	var computeZones, regions []string
+	if computeZones, regions, err = getComputeZones(computeEngineServices[0]); err != nil {
 		return nil, err
 	}
 
@@ -78,95 +125,385 @@ func NewGoogleProducer() (*GoogleProducer, error) {
 		return nil, fmt.Errorf("[Compute Engine] internalIP and externalIP DNS Zone names are the same: %s", pkg.GoogleConfig.InternalIPDNSZone)
 	}
 
-	log.Printf("[Compute Engine] Google producer: project %s, compute zones %v", pkg.GoogleConfig.Project, computeZones)
+	var hostnameTemplate *template.Template
+	if pkg.GoogleConfig.HostnameTemplate != "" {
+		if hostnameTemplate, err = parseHostnameTemplate(pkg.GoogleConfig.HostnameTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	var keySources []string
+	if keySources, err = parseKeySources(pkg.GoogleConfig.KeyPrecedence); err != nil {
+		return nil, err
+	}
+
+	var statusFilter *statusFilter
+	if statusFilter, err = newStatusFilter(pkg.GoogleConfig.InstanceStatuses, time.Duration(pkg.GoogleConfig.InstanceStatusGracePeriod)*time.Second); err != nil {
+		return nil, err
+	}
+
+	if pkg.GoogleConfig.AllZones {
+		log.Printf("[Compute Engine] Google producer: projects %v, all compute zones", projects)
+	} else {
+		log.Printf("[Compute Engine] Google producer: projects %v, compute zones %v", projects, computeZones)
+	}
 	return &GoogleProducer{
-		computeZones:         computeZones,
-		externalIPDNSZone:    pkg.GoogleConfig.ExternalIPDNSZone,
-		internalIPDNSZone:    pkg.GoogleConfig.InternalIPDNSZone,
-		computeEngineService: computeEngineService}, nil
+		computeZones:          computeZones,
+		regions:               regions,
+		forwardingRules:       pkg.GoogleConfig.ForwardingRules,
+		allZones:              pkg.GoogleConfig.AllZones,
+		zoneConcurrency:       pkg.GoogleConfig.ZoneConcurrency,
+		externalIPDNSZone:     pkg.GoogleConfig.ExternalIPDNSZone,
+		internalIPDNSZone:     pkg.GoogleConfig.InternalIPDNSZone,
+		computeEngineServices: computeEngineServices,
+		watchInterval:         watchInterval(),
+		hostnameTemplate:      hostnameTemplate,
+		keySources:            keySources,
+		statusFilter:          statusFilter,
+		aliasHostnameSuffix:   pkg.GoogleConfig.AliasHostnameSuffix}, nil
+}
+
+func watchInterval() time.Duration {
+	if !pkg.GoogleConfig.WatchOperations {
+		return 0
+	}
+	return time.Duration(pkg.GoogleConfig.WatchInterval) * time.Second
 }
 
-func getComputeZones(computeEngineService *computeEngineService) ([]string, error) {
+// getComputeZones returns managed compute zones and their regions
+func getComputeZones(computeEngineService *computeEngineService) ([]string, []string, error) {
 	switch {
+	case pkg.GoogleConfig.AllZones && (pkg.GoogleConfig.Zone != "" || pkg.GoogleConfig.Region != ""):
+		return nil, nil, errors.New("Please provide either --google-zone, --google-region or --google-all-zones")
+	case pkg.GoogleConfig.AllZones:
+		return computeEngineService.getAllZones()
 	case pkg.GoogleConfig.Zone == "" && pkg.GoogleConfig.Region == "":
-		return nil, errors.New("Please provide --google-zone or --google-region")
+		return nil, nil, errors.New("Please provide --google-zone, --google-region or --google-all-zones")
 	case pkg.GoogleConfig.Zone != "" && pkg.GoogleConfig.Region != "":
-		return nil, errors.New("Please provide either --google-zone or --google-region")
+		return nil, nil, errors.New("Please provide either --google-zone or --google-region")
 	case pkg.GoogleConfig.Zone != "":
-		if _, err := computeEngineService.getRegion(pkg.GoogleConfig.Zone); err != nil {
-			return nil, err
+		region, err := computeEngineService.getRegion(pkg.GoogleConfig.Zone)
+		if err != nil {
+			return nil, nil, err
 		}
-		return []string{pkg.GoogleConfig.Zone}, nil
+		return []string{pkg.GoogleConfig.Zone}, []string{region}, nil
 	case pkg.GoogleConfig.Region != "":
 		var managedZones []string
 		var err error
 		if managedZones, err = computeEngineService.getZones(pkg.GoogleConfig.Region); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		return managedZones, nil
+		return managedZones, []string{pkg.GoogleConfig.Region}, nil
 
 	}
-	return nil, errors.New("getManagedZones: Internal error")
+	return nil, nil, errors.New("getManagedZones: Internal error")
 }
 
-// Endpoints provides endpoints read from compute engine.
+// Endpoints provides endpoints read from compute engine of all projects.
+// It records instance statuses for the grace period of the status filter.
 func (gp *GoogleProducer) Endpoints() ([]*pkg.Endpoint, error) {
+	return gp.endpoints(true)
+}
+
+// PreviewEndpoints provides endpoints like Endpoints without recording instance statuses
+func (gp *GoogleProducer) PreviewEndpoints() ([]*pkg.Endpoint, error) {
+	return gp.endpoints(false)
+}
+
+// endpoints provides endpoints of all projects, instance statuses and endpoint gauges are recorded when sync is true
+func (gp *GoogleProducer) endpoints(sync bool) ([]*pkg.Endpoint, error) {
+	zoneInstances, err := gp.instances()
+	if err != nil {
+		return nil, err
+	}
 	endpoints := make([]*pkg.Endpoint, 0, 16)
-	for _, zone := range gp.computeZones {
-		googleInstances, err := gp.computeEngineService.getInstances(zone)
-		if err != nil {
-			return nil, err
-		}
+	for _, v := range zoneInstances {
+		googleInstances := v.instances
 		var internalEndpoints int
 		var externalEndpoints int
-		for _, googleInstance := range googleInstances {
-			internalEndpoint := newEndpoint(&googleInstance, keyInternalIPHostname, keyInternalIPDNSZone, gp.internalIPDNSZone, googleInstance.InternalIP)
-			externalEndpoint := newEndpoint(&googleInstance, keyExternalIPHostname, keyExternalIPDNSZone, gp.externalIPDNSZone, googleInstance.ExternalIP)
-			if internalEndpoint != nil && externalEndpoint != nil && internalEndpoint.DNSZone == externalEndpoint.DNSZone && internalEndpoint.Hostname == externalEndpoint.Hostname {
-				log.Warnf("Instance %s has the same dns name for externalIP %s and internalIP %s", googleInstance.Name, externalEndpoint.IP, internalEndpoint.IP)
-				continue
+		var skippedInstances int
+		for i := range googleInstances {
+			allowed := gp.statusFilter.peek
+			if sync {
+				allowed = gp.statusFilter.allows
 			}
-			if internalEndpoint != nil {
-				endpoints = append(endpoints, internalEndpoint)
-				internalEndpoints++
+			if !allowed(&googleInstances[i]) {
+				log.Debugf("[Compute Engine] Skip instance %s with status %s", googleInstances[i].Name, googleInstances[i].Status)
+				skippedInstances++
+				continue
 			}
-			if externalEndpoint != nil {
-				endpoints = append(endpoints, externalEndpoint)
-				externalEndpoints++
+			for _, nic := range googleInstances[i].NetworkInterfaces {
+				internal, external := gp.nicEndpoints(&googleInstances[i], &nic)
+				endpoints = append(endpoints, internal...)
+				endpoints = append(endpoints, external...)
+				internalEndpoints += len(internal)
+				externalEndpoints += len(external)
 			}
 		}
-		internalEndpointsGauge.WithLabelValues(zone).Set(float64(internalEndpoints))
-		externalEndpointsGauge.WithLabelValues(zone).Set(float64(externalEndpoints))
+		if !sync {
+			continue
+		}
+		internalEndpointsGauge.WithLabelValues(v.project, v.zone).Set(float64(internalEndpoints))
+		externalEndpointsGauge.WithLabelValues(v.project, v.zone).Set(float64(externalEndpoints))
+		skippedInstancesGauge.WithLabelValues(v.project, v.zone).Set(float64(skippedInstances))
+	}
+	if sync {
+		gp.statusFilter.expire()
+	}
+	if gp.forwardingRules {
+		forwardingRulesEndpoints, err := gp.forwardingRulesEndpoints()
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, forwardingRulesEndpoints...)
 	}
 	return endpoints, nil
 }
 
-// ComputeZones provides all compute zones managed by the producer
+// ComputeZones provides all compute zones managed by the producer. Regions and global are included,
+// when forwarding rules are managed.
 func (gp *GoogleProducer) ComputeZones() []string {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	if !gp.forwardingRules {
+		return gp.computeZones
+	}
+	computeZones := append([]string{}, gp.computeZones...)
+	computeZones = append(computeZones, gp.regions...)
+	return append(computeZones, globalComputeZone)
+}
+
+// zones provides compute zones of instances
+func (gp *GoogleProducer) zones() []string {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
 	return gp.computeZones
 }
 
-func newEndpoint(googleInstance *googleInstance, keyHostname string, keyDNSZone string, defaultDNSZone string, ip string) *pkg.Endpoint {
-	if ip != "" {
-		// is mata data or tag present ?
-		hostname, ok1 := googleInstance.Metadata[keyHostname]
-		dnsZone, ok2 := googleInstance.Metadata[keyDNSZone]
-		_, ok3 := googleInstance.Tags[keyHostname]
-		_, ok4 := googleInstance.Tags[keyDNSZone]
-		if ok1 || ok2 || ok3 || ok4 {
-			if hostname == "" {
-				hostname = googleInstance.Name
-			}
-			if dnsZone == "" {
-				dnsZone = defaultDNSZone
+func (gp *GoogleProducer) nicEndpoints(googleInstance *googleInstance, nic *googleNetworkInterface) ([]*pkg.Endpoint, []*pkg.Endpoint) {
+	if !gp.isTagged(googleInstance, nic.Index) {
+		return nil, nil
+	}
+	// default hostname is the evaluated hostname template or the instance name for nic0 and <instance>-nicN for other interfaces.
+	// The template is evaluated only for records without explicit hostname.
+	var name string
+	var nameErr error
+	var evaluated bool
+	defaultName := func() (string, error) {
+		if !evaluated {
+			evaluated = true
+			name, nameErr = gp.hostname(googleInstance, nic.Index)
+			if nameErr == nil && name == "" {
+				name = googleInstance.Name
+				if nic.Index > 0 {
+					name = fmt.Sprintf("%s-nic%d", googleInstance.Name, nic.Index)
+				}
 			}
-			if dnsZone == "" || hostname == "" {
-				log.Warningf("Skip record. Default DNS ComputeZone was not configured: instance name %s, IP %s", googleInstance.Name, ip)
-				return nil
+		}
+		return name, nameErr
+	}
+	aliasName := func() (string, error) {
+		name, err := defaultName()
+		return name + gp.aliasHostnameSuffix, err
+	}
+	var internalIPs []string
+	if nic.InternalIP != "" {
+		internalIPs = append(internalIPs, nic.InternalIP)
+	}
+	if nic.InternalIPv6 != "" {
+		internalIPs = append(internalIPs, nic.InternalIPv6)
+	}
+	internalEndpoints := gp.newEndpoints(googleInstance, nic.Index, defaultName, keyInternalIPHostname, keyInternalIPDNSZone, gp.internalIPDNSZone, internalIPs)
+	externalEndpoints := gp.newEndpoints(googleInstance, nic.Index, defaultName, keyExternalIPHostname, keyExternalIPDNSZone, gp.externalIPDNSZone, nic.ExternalIPs)
+	if len(internalEndpoints) > 0 && len(externalEndpoints) > 0 && internalEndpoints[0].DNSZone == externalEndpoints[0].DNSZone && internalEndpoints[0].Hostname == externalEndpoints[0].Hostname {
+		log.Warnf("Instance %s nic%d has the same dns name for externalIPs %v and internalIPs %v", googleInstance.Name, nic.Index, nic.ExternalIPs, internalIPs)
+		return nil, nil
+	}
+	aliasEndpoints := gp.newEndpoints(googleInstance, nic.Index, aliasName, keyAliasIPHostname, keyAliasIPDNSZone, gp.internalIPDNSZone, nic.AliasIPs)
+	if sameDNSName(aliasEndpoints, internalEndpoints) || sameDNSName(aliasEndpoints, externalEndpoints) {
+		log.Warnf("Instance %s nic%d has the same dns name for alias IPs %v and other IPs, alias IPs are skipped", googleInstance.Name, nic.Index, nic.AliasIPs)
+		aliasEndpoints = nil
+	}
+	internalCNAMEEndpoints := gp.cnameEndpoints(googleInstance, nic.Index, keyInternalIPAliases, internalEndpoints)
+	externalCNAMEEndpoints := gp.cnameEndpoints(googleInstance, nic.Index, keyExternalIPAliases, externalEndpoints)
+	internalSRVEndpoints := gp.srvEndpoints(googleInstance, nic.Index, keyInternalIPSRV, internalEndpoints)
+	externalSRVEndpoints := gp.srvEndpoints(googleInstance, nic.Index, keyExternalIPSRV, externalEndpoints)
+	internalEndpoints = append(internalEndpoints, aliasEndpoints...)
+	internalEndpoints = append(internalEndpoints, internalCNAMEEndpoints...)
+	internalEndpoints = append(internalEndpoints, internalSRVEndpoints...)
+	externalEndpoints = append(externalEndpoints, externalCNAMEEndpoints...)
+	externalEndpoints = append(externalEndpoints, externalSRVEndpoints...)
+	ttl := gp.dnsTTL(googleInstance, nic.Index)
+	setTTL(internalEndpoints, ttl)
+	setTTL(externalEndpoints, ttl)
+	return internalEndpoints, externalEndpoints
+}
+
+// sameDNSName returns true when the first endpoints of a and b have the same hostname in the same DNS zone
+func sameDNSName(a []*pkg.Endpoint, b []*pkg.Endpoint) bool {
+	return len(a) > 0 && len(b) > 0 && a[0].DNSZone == b[0].DNSZone && a[0].Hostname == b[0].Hostname
+}
+
+// dnsTTL returns TTL override of the network interface e.g. dns-ttl=30 or zero, when the value is missing or invalid
+func (gp *GoogleProducer) dnsTTL(googleInstance *googleInstance, index int) int64 {
+	value, ok := gp.lookup(googleInstance, nicKeys(index, keyDNSTTL))
+	if !ok || value == "" {
+		return 0
+	}
+	ttl, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ttl <= 0 {
+		log.Warnf("Ignore invalid %s '%s' of instance %s nic%d", keyDNSTTL, value, googleInstance.Name, index)
+		return 0
+	}
+	return ttl
+}
+
+func setTTL(endpoints []*pkg.Endpoint, ttl int64) {
+	for _, endpoint := range endpoints {
+		endpoint.TTL = ttl
+	}
+}
+
+// cnameEndpoints returns CNAME endpoints of comma separated aliases e.g. db-primary,db pointing at the hostname of endpoints
+func (gp *GoogleProducer) cnameEndpoints(googleInstance *googleInstance, index int, keyAliases string, endpoints []*pkg.Endpoint) []*pkg.Endpoint {
+	value, ok := gp.lookup(googleInstance, nicKeys(index, keyAliases))
+	if !ok || len(endpoints) == 0 {
+		return nil
+	}
+	var result []*pkg.Endpoint
+	for _, alias := range strings.Split(value, ",") {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+		if err := validateHostname(alias); err != nil {
+			log.Warnf("Skip alias of instance %s nic%d: %v", googleInstance.Name, index, err)
+			continue
+		}
+		result = append(result, &pkg.Endpoint{Hostname: alias, DNSZone: endpoints[0].DNSZone, Type: pkg.RecordTypeCNAME, Target: endpoints[0].Hostname,
+			ComputeZone: googleInstance.ComputeZone, Project: googleInstance.Project})
+	}
+	return result
+}
+
+// isTagged returns true when metadata, tags or labels of the network interface are present
+func (gp *GoogleProducer) isTagged(googleInstance *googleInstance, index int) bool {
+	for _, key := range []string{keyInternalIPHostname, keyInternalIPDNSZone, keyExternalIPHostname, keyExternalIPDNSZone, keyAliasIPHostname, keyAliasIPDNSZone} {
+		if _, ok := gp.lookup(googleInstance, nicKeys(index, key)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nicKeys returns the metadata keys of the network interface e.g. nic1-internal-ip-hostname.
+// Keys without prefix belong to nic0.
+func nicKeys(index int, key string) []string {
+	keys := []string{fmt.Sprintf("nic%d-%s", index, key)}
+	if index == 0 {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// lookup returns the value of the first present key, sources of a key are checked in the order of precedence.
+// Tags are the same as metadata with empty value. Label values are decoded.
+func (gp *GoogleProducer) lookup(googleInstance *googleInstance, keys []string) (string, bool) {
+	keySources := gp.keySources
+	if len(keySources) == 0 {
+		keySources = defaultKeySources
+	}
+	for _, key := range keys {
+		for _, keySource := range keySources {
+			switch keySource {
+			case keySourceMetadata:
+				if value, ok := googleInstance.Metadata[key]; ok {
+					return value, true
+				}
+			case keySourceTags:
+				if _, ok := googleInstance.Tags[key]; ok {
+					return "", true
+				}
+			case keySourceLabels:
+				if value, ok := googleInstance.Labels[key]; ok {
+					return decodeLabelValue(value), true
+				}
 			}
-			return &pkg.Endpoint{Hostname: hostname, DNSZone: dnsZone, IP: ip, ComputeZone: googleInstance.ComputeZone}
 		}
+	}
+	return "", false
+}
+
+// decodeLabelValue decodes label-safe value, label values can not contain '.' so it is encoded as '_' e.g. db_prod is
+// db.prod. An underscore is encoded as '_-' e.g. db_-blue_prod is db_blue.prod, DNS labels don't start with '-'.
+func decodeLabelValue(value string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] != '_':
+			buf.WriteByte(value[i])
+		case i+1 < len(value) && value[i+1] == '-':
+			buf.WriteByte('_')
+			i++
+		default:
+			buf.WriteByte('.')
+		}
+	}
+	return buf.String()
+}
+
+// parseKeySources parses comma separated list of metadata, tags and labels
+func parseKeySources(value string) ([]string, error) {
+	if value == "" {
+		return defaultKeySources, nil
+	}
+	keySources := make([]string, 0, 3)
+	seen := make(map[string]struct{})
+	for _, keySource := range strings.Split(value, ",") {
+		keySource = strings.TrimSpace(keySource)
+		switch keySource {
+		case keySourceMetadata, keySourceTags, keySourceLabels:
+		default:
+			return nil, fmt.Errorf("Unknown key source '%s', expected metadata, tags or labels", keySource)
+		}
+		if _, ok := seen[keySource]; ok {
+			return nil, fmt.Errorf("Duplicate key source '%s'", keySource)
+		}
+		seen[keySource] = struct{}{}
+		keySources = append(keySources, keySource)
+	}
+	return keySources, nil
+}
 
+// newEndpoints returns endpoints of the IPs, when the hostname or DNS zone key is present. The default name is used
+// without hostname, the endpoints are skipped when it cannot be evaluated.
+func (gp *GoogleProducer) newEndpoints(googleInstance *googleInstance, index int, defaultName func() (string, error), keyHostname string, keyDNSZone string, defaultDNSZone string, ips []string) []*pkg.Endpoint {
+	if len(ips) == 0 {
+		return nil
+	}
+	// is mata data or tag present ?
+	hostname, ok1 := gp.lookup(googleInstance, nicKeys(index, keyHostname))
+	dnsZone, ok2 := gp.lookup(googleInstance, nicKeys(index, keyDNSZone))
+	if !ok1 && !ok2 {
+		return nil
+	}
+	if hostname == "" {
+		var err error
+		if hostname, err = defaultName(); err != nil {
+			log.Warnf("Skip record of instance %s nic%d, IPs %v: %v", googleInstance.Name, index, ips, err)
+			return nil
+		}
+	}
+	if dnsZone == "" {
+		dnsZone = defaultDNSZone
+	}
+	if dnsZone == "" {
+		log.Warningf("Skip record. Default DNS ComputeZone was not configured: instance name %s, IPs %v", googleInstance.Name, ips)
+		return nil
+	}
+	endpoints := make([]*pkg.Endpoint, 0, len(ips))
+	for _, ip := range ips {
+		endpoints = append(endpoints, &pkg.Endpoint{Hostname: hostname, DNSZone: dnsZone, IP: ip, ComputeZone: googleInstance.ComputeZone, Project: googleInstance.Project})
 	}
-	return nil
+	return endpoints
 }
@@ -0,0 +1,347 @@
+package producers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/compute/v1"
+	"strings"
+	"testing"
+	"time"
+)
+
+func computeInstance(name string, metadata map[string]string, networkInterfaces ...*compute.NetworkInterface) *compute.Instance {
+	instance := &compute.Instance{Name: name, Metadata: &compute.Metadata{}, Tags: &compute.Tags{}, NetworkInterfaces: networkInterfaces}
+	for key, value := range metadata {
+		v := value
+		instance.Metadata.Items = append(instance.Metadata.Items, &compute.MetadataItems{Key: key, Value: &v})
+	}
+	return instance
+}
+
+func TestFromComputeInstance(t *testing.T) {
+	a := assert.New(t)
+
+	instance, err := fromComputeInstance(computeInstance("appliance", nil,
+		&compute.NetworkInterface{
+			NetworkIP:     "10.0.0.2",
+			AccessConfigs: []*compute.AccessConfig{{NatIP: "35.0.0.1"}, {NatIP: "35.0.0.2"}, {}},
+			AliasIpRanges: []*compute.AliasIpRange{{IpCidrRange: "10.1.0.5/32"}, {IpCidrRange: "10.1.0.6"}, {IpCidrRange: "10.2.0.0/24"}},
+		},
+		&compute.NetworkInterface{NetworkIP: "10.10.0.2"},
+		&compute.NetworkInterface{
+			NetworkIP:         "10.20.0.2",
+			Ipv6Address:       "fd20::2",
+			Ipv6AccessConfigs: []*compute.AccessConfig{{ExternalIpv6: "2600:1900::2"}},
+		},
+	))
+	a.NoError(err)
+	a.Equal([]googleNetworkInterface{
+		{Index: 0, InternalIP: "10.0.0.2", ExternalIPs: []string{"35.0.0.1", "35.0.0.2"}, AliasIPs: []string{"10.1.0.5", "10.1.0.6"}},
+		{Index: 1, InternalIP: "10.10.0.2"},
+		{Index: 2, InternalIP: "10.20.0.2", InternalIPv6: "fd20::2", ExternalIPs: []string{"2600:1900::2"}},
+	}, instance.NetworkInterfaces)
+
+	_, err = fromComputeInstance(computeInstance("no-nic", nil))
+	a.Error(err)
+}
+
+func TestNicEndpoints(t *testing.T) {
+	gp := &GoogleProducer{internalIPDNSZone: "internal-zone", externalIPDNSZone: "external-zone", aliasHostnameSuffix: "-alias"}
+	nics := []googleNetworkInterface{
+		{Index: 0, InternalIP: "10.0.0.2", ExternalIPs: []string{"35.0.0.1", "35.0.0.2"}, AliasIPs: []string{"10.1.0.5"}},
+		{Index: 1, InternalIP: "10.10.0.2"},
+	}
+	for _, tc := range []struct {
+		name      string
+		metadata  map[string]string
+		tags      []string
+		labels    map[string]string
+		endpoints []*pkg.Endpoint
+	}{
+		{
+			name: "no keys",
+		},
+		{
+			name:   "label keys with encoded hostname",
+			labels: map[string]string{"nic1-internal-ip-hostname": "db_prod"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db.prod", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:   "label keys with encoded underscore",
+			labels: map[string]string{"nic1-internal-ip-hostname": "db_-blue_prod"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db_blue.prod", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name: "nic0 unprefixed keys",
+			tags: []string{keyInternalIPHostname, keyExternalIPHostname},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a"},
+				{Hostname: "vm", DNSZone: "external-zone", IP: "35.0.0.1", ComputeZone: "zone-a"},
+				{Hostname: "vm", DNSZone: "external-zone", IP: "35.0.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "per interface keys",
+			metadata: map[string]string{"nic0-internal-ip-hostname": "vm-a", "nic1-internal-ip-hostname": "vm-b"},
+			tags:     []string{"nic1-internal-ip-dns-zone"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm-a", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a"},
+				{Hostname: "vm-b", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name: "default hostnames of nic1 and alias IPs",
+			tags: []string{"nic1-internal-ip-hostname", keyAliasIPHostname},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm-alias", DNSZone: "internal-zone", IP: "10.1.0.5", ComputeZone: "zone-a"},
+				{Hostname: "vm-nic1", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "alias IPs with the hostname of internal IP",
+			metadata: map[string]string{keyInternalIPHostname: "db-1", keyAliasIPHostname: "db-1"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db-1", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "aliases",
+			metadata: map[string]string{keyInternalIPHostname: "db-1", keyInternalIPAliases: "db-primary, db", "nic1-internal-ip-aliases": "vm-b", keyExternalIPAliases: "web"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db-1", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a"},
+				{Hostname: "db-primary", DNSZone: "internal-zone", Type: pkg.RecordTypeCNAME, Target: "db-1", ComputeZone: "zone-a"},
+				{Hostname: "db", DNSZone: "internal-zone", Type: pkg.RecordTypeCNAME, Target: "db-1", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "services",
+			metadata: map[string]string{keyInternalIPHostname: "db-1", keyInternalIPSRV: "_postgres._tcp:5432:10:5, _invalid:1:0:0", keyExternalIPSRV: "_http._tcp:80:0:0"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db-1", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a"},
+				{Hostname: "_postgres._tcp", DNSZone: "internal-zone", Type: pkg.RecordTypeSRV, Target: "db-1", Port: 5432, Priority: 10, Weight: 5, ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "ttl",
+			metadata: map[string]string{keyInternalIPHostname: "db-1", keyDNSTTL: "3600", "nic1-internal-ip-hostname": "", "nic1-dns-ttl": "invalid"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db-1", DNSZone: "internal-zone", IP: "10.0.0.2", ComputeZone: "zone-a", TTL: 3600},
+				{Hostname: "vm-nic1", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "same dns name for internal and external IPs",
+			metadata: map[string]string{keyInternalIPDNSZone: "zone", keyExternalIPDNSZone: "zone"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			instance := &googleInstance{Name: "vm", ComputeZone: "zone-a", NetworkInterfaces: nics, Metadata: tc.metadata, Tags: map[string]struct{}{}, Labels: tc.labels}
+			for _, tag := range tc.tags {
+				instance.Tags[tag] = struct{}{}
+			}
+			var endpoints []*pkg.Endpoint
+			for _, nic := range instance.NetworkInterfaces {
+				internal, external := gp.nicEndpoints(instance, &nic)
+				endpoints = append(endpoints, internal...)
+				endpoints = append(endpoints, external...)
+			}
+			assert.Equal(t, tc.endpoints, endpoints)
+		})
+	}
+}
+
+func TestLookupPrecedence(t *testing.T) {
+	instance := &googleInstance{
+		Metadata: map[string]string{keyInternalIPHostname: "from-metadata"},
+		Tags:     map[string]struct{}{keyInternalIPHostname: {}},
+		Labels:   map[string]string{keyInternalIPHostname: "from-labels", "nic1-internal-ip-hostname": "from-nic1-labels"},
+	}
+	for _, tc := range []struct {
+		precedence string
+		keys       []string
+		value      string
+	}{
+		{precedence: "", keys: nicKeys(0, keyInternalIPHostname), value: "from-metadata"},
+		{precedence: "labels,metadata,tags", keys: nicKeys(0, keyInternalIPHostname), value: "from-labels"},
+		{precedence: "tags,labels", keys: nicKeys(0, keyInternalIPHostname), value: ""},
+		{precedence: "metadata,tags", keys: nicKeys(1, keyInternalIPHostname)},
+		{precedence: "metadata,labels", keys: nicKeys(1, keyInternalIPHostname), value: "from-nic1-labels"},
+	} {
+		t.Run(tc.precedence, func(t *testing.T) {
+			keySources, err := parseKeySources(tc.precedence)
+			assert.NoError(t, err)
+			value, ok := (&GoogleProducer{keySources: keySources}).lookup(instance, tc.keys)
+			assert.Equal(t, tc.value, value)
+			assert.Equal(t, tc.value != "" || tc.precedence == "tags,labels", ok)
+		})
+	}
+
+	_, err := parseKeySources("metadata,annotations")
+	assert.Error(t, err)
+	_, err = parseKeySources("labels,labels")
+	assert.Error(t, err)
+}
+
+func TestHostnameTemplate(t *testing.T) {
+	tmpl, err := parseHostnameTemplate("{{.Name}}.{{.Zone}}")
+	assert.NoError(t, err)
+	gp := &GoogleProducer{internalIPDNSZone: "internal-zone", hostnameTemplate: tmpl, aliasHostnameSuffix: "-alias"}
+	nic := googleNetworkInterface{Index: 1, InternalIP: "10.10.0.2", AliasIPs: []string{"10.1.0.5"}}
+
+	for _, tc := range []struct {
+		name      string
+		metadata  map[string]string
+		endpoints []*pkg.Endpoint
+	}{
+		{
+			name:     "global template",
+			metadata: map[string]string{"nic1-internal-ip-dns-zone": "", "nic1-alias-ip-dns-zone": ""},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm-nic1.zone-a", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+				{Hostname: "vm-nic1.zone-a-alias", DNSZone: "internal-zone", IP: "10.1.0.5", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "instance template",
+			metadata: map[string]string{"nic1-internal-ip-dns-zone": "", keyHostnameTemplate: "{{.Metadata.role}}-{{.Index}}", "role": "db"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "db-1", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "explicit hostname",
+			metadata: map[string]string{"nic1-internal-ip-hostname": "vm-b"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm-b", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "missing metadata key",
+			metadata: map[string]string{"nic1-internal-ip-dns-zone": "", keyHostnameTemplate: "{{.Metadata.role}}"},
+		},
+		{
+			name:     "explicit hostname with missing metadata key",
+			metadata: map[string]string{"nic1-internal-ip-hostname": "vm-b", "nic1-alias-ip-dns-zone": "", keyHostnameTemplate: "{{.Metadata.role}}"},
+			endpoints: []*pkg.Endpoint{
+				{Hostname: "vm-b", DNSZone: "internal-zone", IP: "10.10.0.2", ComputeZone: "zone-a"},
+			},
+		},
+		{
+			name:     "invalid hostname",
+			metadata: map[string]string{"nic1-internal-ip-dns-zone": "", keyHostnameTemplate: "{{.Index}}-{{.Name}}"},
+		},
+		{
+			name:     "invalid template",
+			metadata: map[string]string{"nic1-internal-ip-dns-zone": "", keyHostnameTemplate: "{{.Name"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			instance := &googleInstance{Name: "vm", ComputeZone: "zone-a", NetworkInterfaces: []googleNetworkInterface{nic}, Metadata: tc.metadata}
+			internal, external := gp.nicEndpoints(instance, &nic)
+			assert.Equal(t, tc.endpoints, append(internal, external...))
+		})
+	}
+}
+
+func TestParseService(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		service *service
+	}{
+		{value: "_postgres._tcp:5432:10:5", service: &service{name: "_postgres._tcp", port: 5432, priority: 10, weight: 5}},
+		{value: "_sip._udp:5060:0:0", service: &service{name: "_sip._udp", port: 5060}},
+		{value: "_postgres._tcp:5432"},
+		{value: "postgres._tcp:5432:10:5"},
+		{value: "_postgres:5432:10:5"},
+		{value: "_postgres._tcp:0:10:5"},
+		{value: "_postgres._tcp:5432:10:65536"},
+		{value: "_postgres._tcp:port:10:5"},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			s, err := parseService(tc.value)
+			assert.Equal(t, tc.service, s)
+			assert.Equal(t, tc.service != nil, err == nil, err)
+		})
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	for _, tc := range []struct {
+		hostname string
+		valid    bool
+	}{
+		{hostname: "vm", valid: true},
+		{hostname: "vm-1.europe-west1-c", valid: true},
+		{hostname: ""},
+		{hostname: "1vm"},
+		{hostname: "vm-"},
+		{hostname: "vm..zone"},
+		{hostname: "vm_1"},
+		{hostname: strings.Repeat("a", 64)},
+		{hostname: strings.Repeat("a.", 127) + "a"},
+	} {
+		t.Run(tc.hostname, func(t *testing.T) {
+			err := validateHostname(tc.hostname)
+			assert.Equal(t, tc.valid, err == nil, err)
+		})
+	}
+}
+
+func TestDecodeLabelValue(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected string
+	}{
+		{value: "db-prod", expected: "db-prod"},
+		{value: "db_prod", expected: "db.prod"},
+		{value: "db_-prod", expected: "db_prod"},
+		{value: "_-postgres__-tcp", expected: "_postgres._tcp"},
+		{value: "db_-_prod", expected: "db_.prod"},
+		{value: "db_", expected: "db."},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			assert.Equal(t, tc.expected, decodeLabelValue(tc.value))
+		})
+	}
+}
+
+func TestInstanceOperations(t *testing.T) {
+	a := assert.New(t)
+
+	last, _ := time.Parse(time.RFC3339, "2026-10-17T10:00:00Z")
+	watermark := &operationsWatermark{insertTime: last, endTime: last}
+	operations := []*compute.Operation{
+		{OperationType: "stop", TargetLink: "zones/zone-a/instances/vm-4", Status: "RUNNING", InsertTime: "2026-10-17T10:00:35Z"},
+		{OperationType: "insert", TargetLink: "zones/zone-a/instances/vm-3", Status: "DONE", InsertTime: "2026-10-17T10:00:25Z", EndTime: "2026-10-17T10:00:30Z"},
+		{OperationType: "setDeletionProtection", TargetLink: "zones/zone-a/instances/vm-2", Status: "DONE", InsertTime: "2026-10-17T10:00:20Z", EndTime: "2026-10-17T10:00:40Z"},
+		{OperationType: "delete", TargetLink: "zones/zone-a/disks/disk-1", Status: "DONE", InsertTime: "2026-10-17T10:00:15Z", EndTime: "2026-10-17T10:00:20Z"},
+		{OperationType: "setMetadata", TargetLink: "zones/zone-a/instances/vm-1", Status: "DONE", InsertTime: "2026-10-17T10:00:05Z", EndTime: "2026-10-17T10:00:10Z"},
+	}
+	finished := instanceOperations(operations, watermark)
+	a.Equal([]*compute.Operation{operations[1], operations[4]}, finished)
+	a.Equal("2026-10-17T10:00:40Z", watermark.endTime.Format(time.RFC3339))
+	// the running operation is listed again
+	a.True(watermark.insertTime.Before(mustParseTime("2026-10-17T10:00:35Z")))
+	a.True(watermark.insertTime.After(mustParseTime("2026-10-17T10:00:34Z")))
+
+	operations[0].Status = "DONE"
+	operations[0].EndTime = "2026-10-17T10:00:50Z"
+	finished = instanceOperations(operations[:1], watermark)
+	a.Equal([]*compute.Operation{operations[0]}, finished)
+	a.Equal("2026-10-17T10:00:50Z", watermark.endTime.Format(time.RFC3339))
+	a.Equal("2026-10-17T10:00:35Z", watermark.insertTime.Format(time.RFC3339))
+
+	a.Empty(instanceOperations(operations, watermark))
+}
+
+func mustParseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,94 @@
+package producers
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	keyHostnameTemplate = "hostname-template"
+	maxHostnameLength   = 253
+)
+
+var (
+	// label of RFC1035 domain name
+	rfc1035Label = regexp.MustCompile(`^[a-zA-Z]([-a-zA-Z0-9]{0,61}[a-zA-Z0-9])?$`)
+)
+
+// hostnameTemplateData is available in hostname templates e.g. {{.Name}}.{{.Zone}} or {{.Metadata.role}}-{{.Index}}
+type hostnameTemplateData struct {
+	// instance name
+	Name string
+	// compute zone
+	Zone string
+	// index of the network interface
+	Index int
+	// instance metadata, missing keys are errors
+	Metadata map[string]string
+	// instance labels, missing keys are errors
+	Labels map[string]string
+}
+
+func parseHostnameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("hostname").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid hostname template '%s': %v", text, err)
+	}
+	return tmpl, nil
+}
+
+// hostname evaluates the instance hostname template or the producer one. It returns an empty string without templates.
+// When the hostname of a network interface other than nic0 does not depend on the index, -nicN is added to its first
+// label e.g. vm-nic1.europe-west1-c, so that network interfaces have distinct hostnames.
+func (gp *GoogleProducer) hostname(googleInstance *googleInstance, index int) (string, error) {
+	tmpl := gp.hostnameTemplate
+	if text, ok := googleInstance.Metadata[keyHostnameTemplate]; ok && text != "" {
+		var err error
+		if tmpl, err = parseHostnameTemplate(text); err != nil {
+			return "", err
+		}
+	}
+	if tmpl == nil {
+		return "", nil
+	}
+	hostname, err := executeHostnameTemplate(tmpl, googleInstance, index)
+	if err != nil {
+		return "", err
+	}
+	if index > 0 {
+		if nic0Hostname, err := executeHostnameTemplate(tmpl, googleInstance, 0); err == nil && nic0Hostname == hostname {
+			labels := strings.SplitN(hostname, ".", 2)
+			labels[0] = fmt.Sprintf("%s-nic%d", labels[0], index)
+			hostname = strings.Join(labels, ".")
+		}
+	}
+	if err := validateHostname(hostname); err != nil {
+		return "", err
+	}
+	return hostname, nil
+}
+
+func executeHostnameTemplate(tmpl *template.Template, googleInstance *googleInstance, index int) (string, error) {
+	data := &hostnameTemplateData{Name: googleInstance.Name, Zone: googleInstance.ComputeZone, Index: index, Metadata: googleInstance.Metadata, Labels: googleInstance.Labels}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("Unable to evaluate hostname template: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// validateHostname checks that the hostname is a RFC1035 domain name
+func validateHostname(hostname string) error {
+	if hostname == "" || len(hostname) > maxHostnameLength {
+		return fmt.Errorf("Invalid hostname '%s': length must be 1-%d characters", hostname, maxHostnameLength)
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if !rfc1035Label.MatchString(label) {
+			return fmt.Errorf("Invalid hostname '%s': label '%s' does not comply with RFC1035", hostname, label)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package producers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/prometheus/client_golang/prometheus"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// https://cloud.google.com/compute/docs/instances/instance-life-cycle
+	instanceStatuses = map[string]struct{}{
+		"PROVISIONING": {},
+		"STAGING":      {},
+		"RUNNING":      {},
+		"STOPPING":     {},
+		"STOPPED":      {},
+		"SUSPENDING":   {},
+		"SUSPENDED":    {},
+		"REPAIRING":    {},
+		"TERMINATED":   {},
+	}
+
+	skippedInstancesGauge *prometheus.GaugeVec
+)
+
+func init() {
+	skippedInstancesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "google_producer",
+		Name:      "skipped_instances",
+		Help:      "Number of instances skipped due to their status.",
+	},
+		[]string{"project", "compute_zone"},
+	)
+	prometheus.MustRegister(skippedInstancesGauge)
+}
+
+// statusFilter allows instances with one of the allowed statuses. Instances which had an allowed status
+// are still allowed during the grace period after they left it, so that short transitions e.g. a restart don't remove
+// their records.
+type statusFilter struct {
+	allowed     map[string]struct{}
+	gracePeriod time.Duration
+	now         func() time.Time
+
+	mu sync.Mutex
+	// instances which had an allowed status, key is <project>/<compute-zone>/<name>
+	statuses map[string]*instanceStatus
+}
+
+// instanceStatus tracks an instance which had an allowed status
+type instanceStatus struct {
+	// last time the instance was listed
+	lastSeen time.Time
+	// first time the instance was listed with a status which is not allowed, zero while the status is allowed
+	notAllowedSince time.Time
+}
+
+// newStatusFilter creates statusFilter from comma separated statuses. It returns nil, when all statuses are allowed.
+func newStatusFilter(statuses string, gracePeriod time.Duration) (*statusFilter, error) {
+	allowed := make(map[string]struct{})
+	for _, status := range strings.Split(statuses, ",") {
+		status = strings.ToUpper(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+		if _, ok := instanceStatuses[status]; !ok {
+			return nil, fmt.Errorf("Unknown instance status '%s'", status)
+		}
+		allowed[status] = struct{}{}
+	}
+	if len(allowed) == 0 {
+		return nil, nil
+	}
+	return &statusFilter{allowed: allowed, gracePeriod: gracePeriod, now: time.Now, statuses: make(map[string]*instanceStatus)}, nil
+}
+
+// allows returns true when the instance status is allowed or it left the allowed statuses within the grace period.
+// The grace period is measured from the first time the instance is listed with a status which is not allowed,
+// so it doesn't depend on the sync interval.
+func (f *statusFilter) allows(googleInstance *googleInstance) bool {
+	if f == nil {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := googleInstance.Project + "/" + googleInstance.ComputeZone + "/" + googleInstance.Name
+	now := f.now()
+	if _, ok := f.allowed[googleInstance.Status]; ok {
+		f.statuses[key] = &instanceStatus{lastSeen: now}
+		return true
+	}
+	status, ok := f.statuses[key]
+	if !ok {
+		return false
+	}
+	status.lastSeen = now
+	if status.notAllowedSince.IsZero() {
+		status.notAllowedSince = now
+	}
+	if now.Sub(status.notAllowedSince) < f.gracePeriod {
+		log.Debugf("[Compute Engine] Instance %s is %s, grace period ends at %s", googleInstance.Name, googleInstance.Status, status.notAllowedSince.Add(f.gracePeriod))
+		return true
+	}
+	delete(f.statuses, key)
+	return false
+}
+
+// peek returns the decision of allows without recording the instance, so that previews don't change the grace periods
+// of the synchronization
+func (f *statusFilter) peek(googleInstance *googleInstance) bool {
+	if f == nil {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.allowed[googleInstance.Status]; ok {
+		return true
+	}
+	status, ok := f.statuses[googleInstance.Project+"/"+googleInstance.ComputeZone+"/"+googleInstance.Name]
+	if !ok {
+		return false
+	}
+	if status.notAllowedSince.IsZero() {
+		return f.gracePeriod > 0
+	}
+	return f.now().Sub(status.notAllowedSince) < f.gracePeriod
+}
+
+// expire forgets instances which were not listed within the grace period e.g. deleted instances
+func (f *statusFilter) expire() {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	for key, status := range f.statuses {
+		if now.Sub(status.lastSeen) >= f.gracePeriod {
+			delete(f.statuses, key)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package producers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStatusFilter(t *testing.T) {
+	a := assert.New(t)
+
+	filter, err := newStatusFilter("", time.Minute)
+	a.NoError(err)
+	a.Nil(filter)
+	a.True(filter.allows(&googleInstance{Name: "vm", Status: "TERMINATED"}))
+
+	_, err = newStatusFilter("RUNNING,STARTED", time.Minute)
+	a.Error(err)
+
+	filter, err = newStatusFilter("running", time.Minute)
+	a.NoError(err)
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	filter.now = func() time.Time { return now }
+
+	running := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "RUNNING"}
+	stopping := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "STOPPING"}
+	provisioning := &googleInstance{Name: "vm-2", ComputeZone: "zone-a", Status: "PROVISIONING"}
+
+	a.True(filter.allows(running))
+	a.False(filter.allows(provisioning), "instance was never running")
+
+	now = now.Add(30 * time.Second)
+	a.True(filter.allows(stopping), "within grace period")
+	a.True(filter.allows(running))
+
+	now = now.Add(30 * time.Second)
+	a.True(filter.allows(stopping), "grace period is restarted by running status")
+	now = now.Add(59 * time.Second)
+	a.True(filter.allows(stopping), "grace period starts when the instance left running status")
+	now = now.Add(time.Second)
+	a.False(filter.allows(stopping), "grace period is over")
+	a.Empty(filter.statuses)
+
+	a.True(filter.allows(running))
+	now = now.Add(time.Minute)
+	filter.expire()
+	a.Empty(filter.statuses, "instance which was not listed is forgotten")
+}
+
+func TestStatusFilterSyncIntervalAboveGracePeriod(t *testing.T) {
+	a := assert.New(t)
+
+	filter, err := newStatusFilter("RUNNING", time.Minute)
+	a.NoError(err)
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	filter.now = func() time.Time { return now }
+
+	running := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "RUNNING"}
+	stopping := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "STOPPING"}
+
+	// sync interval is 5 minutes
+	a.True(filter.allows(running))
+	filter.expire()
+	now = now.Add(5 * time.Minute)
+	a.True(filter.allows(stopping), "grace period starts with the first sync seeing the instance stopping")
+	filter.expire()
+	a.Len(filter.statuses, 1)
+	now = now.Add(5 * time.Minute)
+	a.False(filter.allows(stopping), "grace period is over")
+}
+
+func TestStatusFilterPeek(t *testing.T) {
+	a := assert.New(t)
+
+	filter, err := newStatusFilter("RUNNING", time.Minute)
+	a.NoError(err)
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	filter.now = func() time.Time { return now }
+
+	running := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "RUNNING"}
+	stopping := &googleInstance{Name: "vm-1", ComputeZone: "zone-a", Status: "STOPPING"}
+
+	a.False(filter.peek(stopping), "instance was never running")
+	a.True(filter.allows(running))
+
+	// previews between syncs don't start the grace period
+	now = now.Add(5 * time.Minute)
+	a.True(filter.peek(stopping))
+	a.True(filter.statuses["/zone-a/vm-1"].notAllowedSince.IsZero())
+	now = now.Add(5 * time.Minute)
+	a.True(filter.allows(stopping), "grace period starts with the sync")
+	now = now.Add(time.Minute)
+	a.False(filter.peek(stopping), "grace period is over")
+	a.Len(filter.statuses, 1)
+}
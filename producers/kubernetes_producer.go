@@ -0,0 +1,226 @@
+package producers
+
+import (
+	"errors"
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	annotationPrefix = "buddy/"
+
+	annotationInternalIPDNSZone  = annotationPrefix + keyInternalIPDNSZone
+	annotationExternalIPDNSZone  = annotationPrefix + keyExternalIPDNSZone
+	annotationInternalIPHostname = annotationPrefix + keyInternalIPHostname
+	annotationExternalIPHostname = annotationPrefix + keyExternalIPHostname
+
+	kindService = "service"
+	kindNode    = "node"
+)
+
+var (
+	kubernetesInternalEndpointsGauge *prometheus.GaugeVec
+	kubernetesExternalEndpointsGauge *prometheus.GaugeVec
+)
+
+func init() {
+	kubernetesInternalEndpointsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "kubernetes_producer",
+		Name:      "internal_endpoints",
+		Help:      "Number of internal endpoints of services and nodes.",
+	},
+		[]string{"kind"},
+	)
+	prometheus.MustRegister(kubernetesInternalEndpointsGauge)
+
+	kubernetesExternalEndpointsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "buddy",
+		Subsystem: "kubernetes_producer",
+		Name:      "external_endpoints",
+		Help:      "Number of external endpoints of services and nodes.",
+	},
+		[]string{"kind"},
+	)
+	prometheus.MustRegister(kubernetesExternalEndpointsGauge)
+}
+
+// KubernetesProducer reads annotated services and nodes from kubernetes
+type KubernetesProducer struct {
+	client            kubernetes.Interface
+	namespace         string
+	clusterName       string
+	externalIPDNSZone string
+	internalIPDNSZone string
+}
+
+// NewKubernetesProducer creates new KubernetesProducer
+func NewKubernetesProducer() (*KubernetesProducer, error) {
+	if pkg.KubernetesConfig.ClusterName == "" {
+		return nil, errors.New("Please provide --kubernetes-cluster-name")
+	}
+	if pkg.GoogleConfig.ExternalIPDNSZone == pkg.GoogleConfig.InternalIPDNSZone && pkg.GoogleConfig.ExternalIPDNSZone != "" {
+		return nil, fmt.Errorf("[Kubernetes] internalIP and externalIP DNS Zone names are the same: %s", pkg.GoogleConfig.InternalIPDNSZone)
+	}
+	client, err := NewKubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Kubernetes] Kubernetes producer: cluster %s, namespace '%s'", pkg.KubernetesConfig.ClusterName, pkg.KubernetesConfig.Namespace)
+	return &KubernetesProducer{
+		client:            client,
+		namespace:         pkg.KubernetesConfig.Namespace,
+		clusterName:       pkg.KubernetesConfig.ClusterName,
+		externalIPDNSZone: pkg.GoogleConfig.ExternalIPDNSZone,
+		internalIPDNSZone: pkg.GoogleConfig.InternalIPDNSZone,
+	}, nil
+}
+
+// NewKubernetesClient creates kubernetes client from kubeconfig or in-cluster configuration
+func NewKubernetesClient() (kubernetes.Interface, error) {
+	var config *rest.Config
+	var err error
+	if pkg.KubernetesConfig.Kubeconfig != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", pkg.KubernetesConfig.Kubeconfig)
+	} else {
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[Kubernetes] Unable to create client configuration: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("[Kubernetes] Unable to create client: %v", err)
+	}
+	return client, nil
+}
+
+// Endpoints provides endpoints of annotated services and nodes.
+func (kp *KubernetesProducer) Endpoints() ([]*pkg.Endpoint, error) {
+	endpoints := make([]*pkg.Endpoint, 0, 16)
+
+	services, err := kp.client.CoreV1().Services(kp.namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("[Kubernetes] Unable to retrieve list of services: %v", err)
+	}
+	var internalEndpoints int
+	var externalEndpoints int
+	for _, service := range services.Items {
+		internal, external := kp.serviceEndpoints(&service)
+		endpoints = append(endpoints, internal...)
+		endpoints = append(endpoints, external...)
+		internalEndpoints += len(internal)
+		externalEndpoints += len(external)
+	}
+	kubernetesInternalEndpointsGauge.WithLabelValues(kindService).Set(float64(internalEndpoints))
+	kubernetesExternalEndpointsGauge.WithLabelValues(kindService).Set(float64(externalEndpoints))
+
+	nodes, err := kp.client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("[Kubernetes] Unable to retrieve list of nodes: %v", err)
+	}
+	internalEndpoints = 0
+	externalEndpoints = 0
+	for _, node := range nodes.Items {
+		internal, external := kp.nodeEndpoints(&node)
+		endpoints = append(endpoints, internal...)
+		endpoints = append(endpoints, external...)
+		internalEndpoints += len(internal)
+		externalEndpoints += len(external)
+	}
+	kubernetesInternalEndpointsGauge.WithLabelValues(kindNode).Set(float64(internalEndpoints))
+	kubernetesExternalEndpointsGauge.WithLabelValues(kindNode).Set(float64(externalEndpoints))
+
+	return endpoints, nil
+}
+
+// ComputeZones provides the cluster name, which is used instead of compute zone
+func (kp *KubernetesProducer) ComputeZones() []string {
+	return []string{kp.clusterName}
+}
+
+func (kp *KubernetesProducer) serviceEndpoints(service *v1.Service) ([]*pkg.Endpoint, []*pkg.Endpoint) {
+	// default hostname <service>.<namespace>
+	name := service.Name + "." + service.Namespace
+
+	var internalIPs []string
+	// ClusterIPs contains IPv4 and IPv6 addresses of dual-stack services
+	clusterIPs := service.Spec.ClusterIPs
+	if len(clusterIPs) == 0 && service.Spec.ClusterIP != "" {
+		clusterIPs = []string{service.Spec.ClusterIP}
+	}
+	for _, clusterIP := range clusterIPs {
+		if clusterIP != "" && clusterIP != v1.ClusterIPNone {
+			internalIPs = append(internalIPs, clusterIP)
+		}
+	}
+	var externalIPs []string
+	if service.Spec.Type == v1.ServiceTypeLoadBalancer {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				externalIPs = append(externalIPs, ingress.IP)
+			}
+		}
+	}
+	return kp.newEndpoints(kindService, name, service.Annotations, internalIPs, externalIPs)
+}
+
+func (kp *KubernetesProducer) nodeEndpoints(node *v1.Node) ([]*pkg.Endpoint, []*pkg.Endpoint) {
+	var internalIPs []string
+	var externalIPs []string
+	for _, address := range node.Status.Addresses {
+		switch address.Type {
+		case v1.NodeInternalIP:
+			internalIPs = append(internalIPs, address.Address)
+		case v1.NodeExternalIP:
+			externalIPs = append(externalIPs, address.Address)
+		}
+	}
+	return kp.newEndpoints(kindNode, node.Name, node.Annotations, internalIPs, externalIPs)
+}
+
+func (kp *KubernetesProducer) newEndpoints(kind string, name string, annotations map[string]string, internalIPs []string, externalIPs []string) ([]*pkg.Endpoint, []*pkg.Endpoint) {
+	internalEndpoints := kp.newAnnotatedEndpoints(kind, name, annotations, annotationInternalIPHostname, annotationInternalIPDNSZone, kp.internalIPDNSZone, internalIPs)
+	externalEndpoints := kp.newAnnotatedEndpoints(kind, name, annotations, annotationExternalIPHostname, annotationExternalIPDNSZone, kp.externalIPDNSZone, externalIPs)
+	if len(internalEndpoints) > 0 && len(externalEndpoints) > 0 && internalEndpoints[0].DNSZone == externalEndpoints[0].DNSZone && internalEndpoints[0].Hostname == externalEndpoints[0].Hostname {
+		log.Warnf("[Kubernetes] %s %s has the same dns name for externalIPs %v and internalIPs %v", kind, name, externalIPs, internalIPs)
+		return nil, nil
+	}
+	return internalEndpoints, externalEndpoints
+}
+
+func (kp *KubernetesProducer) newAnnotatedEndpoints(kind string, name string, annotations map[string]string, keyHostname string, keyDNSZone string, defaultDNSZone string, ips []string) []*pkg.Endpoint {
+	if len(ips) == 0 {
+		return nil
+	}
+	// is annotation present ?
+	hostname, ok1 := annotations[keyHostname]
+	dnsZone, ok2 := annotations[keyDNSZone]
+	if !ok1 && !ok2 {
+		return nil
+	}
+	if hostname == "" {
+		hostname = name
+	}
+	if dnsZone == "" {
+		dnsZone = defaultDNSZone
+	}
+	if dnsZone == "" {
+		log.Warningf("[Kubernetes] Skip record. Default DNS zone was not configured: %s %s, IPs %v", kind, name, ips)
+		return nil
+	}
+	endpoints := make([]*pkg.Endpoint, 0, len(ips))
+	for _, ip := range ips {
+		endpoints = append(endpoints, &pkg.Endpoint{Hostname: hostname, DNSZone: dnsZone, IP: ip, ComputeZone: kp.clusterName})
+	}
+	return endpoints
+}
@@ -0,0 +1,119 @@
+package producers
+
+import (
+	"github.com/everesio/buddy/pkg"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"testing"
+)
+
+func newFakeKubernetesProducer(objects ...fakeObject) *KubernetesProducer {
+	client := fake.NewSimpleClientset()
+	for _, o := range objects {
+		o(client)
+	}
+	return &KubernetesProducer{
+		client:            client,
+		clusterName:       "gke-cluster-1",
+		internalIPDNSZone: "internal-example-com",
+		externalIPDNSZone: "external-example-com",
+	}
+}
+
+type fakeObject func(client *fake.Clientset)
+
+func fakeService(namespace string, name string, serviceType v1.ServiceType, clusterIP string, annotations map[string]string, ingressIPs ...string) fakeObject {
+	return func(client *fake.Clientset) {
+		s := &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+			Spec:       v1.ServiceSpec{Type: serviceType, ClusterIP: clusterIP},
+		}
+		for _, ip := range ingressIPs {
+			s.Status.LoadBalancer.Ingress = append(s.Status.LoadBalancer.Ingress, v1.LoadBalancerIngress{IP: ip})
+		}
+		client.Tracker().Add(s)
+	}
+}
+
+func fakeNode(name string, internalIP string, externalIP string, annotations map[string]string) fakeObject {
+	return func(client *fake.Clientset) {
+		n := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations}}
+		if internalIP != "" {
+			n.Status.Addresses = append(n.Status.Addresses, v1.NodeAddress{Type: v1.NodeInternalIP, Address: internalIP})
+		}
+		if externalIP != "" {
+			n.Status.Addresses = append(n.Status.Addresses, v1.NodeAddress{Type: v1.NodeExternalIP, Address: externalIP})
+		}
+		client.Tracker().Add(n)
+	}
+}
+
+func TestKubernetesProducerComputeZones(t *testing.T) {
+	a := assert.New(t)
+	kp := newFakeKubernetesProducer()
+	a.Equal([]string{"gke-cluster-1"}, kp.ComputeZones())
+}
+
+func TestKubernetesProducerEndpoints(t *testing.T) {
+	a := assert.New(t)
+
+	kp := newFakeKubernetesProducer(
+		// not annotated
+		fakeService("default", "kubernetes", v1.ServiceTypeClusterIP, "10.0.0.1", nil),
+		// internal record for ClusterIP
+		fakeService("default", "db", v1.ServiceTypeClusterIP, "10.0.0.2", map[string]string{
+			"buddy/internal-ip-dns-zone": "",
+		}),
+		// headless service
+		fakeService("default", "headless", v1.ServiceTypeClusterIP, v1.ClusterIPNone, map[string]string{
+			"buddy/internal-ip-dns-zone": "",
+		}),
+		// external records for load balancer ingress
+		fakeService("web", "frontend", v1.ServiceTypeLoadBalancer, "10.0.0.3", map[string]string{
+			"buddy/external-ip-hostname": "www",
+			"buddy/external-ip-dns-zone": "services-example-com",
+		}, "104.155.9.1", "104.155.9.2"),
+		fakeNode("node-1", "10.132.0.1", "130.211.0.1", map[string]string{
+			"buddy/internal-ip-hostname": "",
+			"buddy/external-ip-hostname": "",
+		}),
+		fakeNode("node-2", "10.132.0.2", "", map[string]string{
+			"buddy/internal-ip-hostname": "worker",
+		}),
+		// the same DNS name for internal and external IP
+		fakeNode("node-3", "10.132.0.3", "130.211.0.3", map[string]string{
+			"buddy/internal-ip-dns-zone": "example-com",
+			"buddy/external-ip-dns-zone": "example-com",
+		}),
+	)
+
+	endpoints, err := kp.Endpoints()
+	a.NoError(err)
+	a.Len(endpoints, 6)
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "db.default", DNSZone: "internal-example-com", IP: "10.0.0.2", ComputeZone: "gke-cluster-1"})
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "www", DNSZone: "services-example-com", IP: "104.155.9.1", ComputeZone: "gke-cluster-1"})
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "www", DNSZone: "services-example-com", IP: "104.155.9.2", ComputeZone: "gke-cluster-1"})
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "node-1", DNSZone: "internal-example-com", IP: "10.132.0.1", ComputeZone: "gke-cluster-1"})
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "node-1", DNSZone: "external-example-com", IP: "130.211.0.1", ComputeZone: "gke-cluster-1"})
+	a.Contains(endpoints, &pkg.Endpoint{Hostname: "worker", DNSZone: "internal-example-com", IP: "10.132.0.2", ComputeZone: "gke-cluster-1"})
+}
+
+func TestKubernetesProducerNamespace(t *testing.T) {
+	a := assert.New(t)
+
+	kp := newFakeKubernetesProducer(
+		fakeService("default", "db", v1.ServiceTypeClusterIP, "10.0.0.2", map[string]string{
+			"buddy/internal-ip-dns-zone": "",
+		}),
+		fakeService("web", "cache", v1.ServiceTypeClusterIP, "10.0.0.3", map[string]string{
+			"buddy/internal-ip-dns-zone": "",
+		}),
+	)
+	kp.namespace = "web"
+
+	endpoints, err := kp.Endpoints()
+	a.NoError(err)
+	a.Equal([]*pkg.Endpoint{{Hostname: "cache.web", DNSZone: "internal-example-com", IP: "10.0.0.3", ComputeZone: "gke-cluster-1"}}, endpoints)
+}
@@ -15,11 +15,37 @@ type Producer interface {
 	Endpoints() ([]*pkg.Endpoint, error)
 }
 
+// Watcher is implemented by producers which can signal that endpoints may have changed
+type Watcher interface {
+
+	// sends to changes until stop is closed, the send must not block
+	Watch(changes chan<- struct{}, stop <-chan struct{})
+}
+
+// Previewer is implemented by producers whose Endpoints records state used by the following calls
+type Previewer interface {
+
+	// endpoints as provided by Endpoints without changing the producer state
+	PreviewEndpoints() ([]*pkg.Endpoint, error)
+}
+
+// PreviewEndpoints provides endpoints of the producer without changing the state of the synchronization
+func PreviewEndpoints(producer Producer) ([]*pkg.Endpoint, error) {
+	if previewer, ok := producer.(Previewer); ok {
+		return previewer.PreviewEndpoints()
+	}
+	return producer.Endpoints()
+}
+
 // New creates a new producer
 func New(name string) (Producer, error) {
 	switch name {
 	case "google":
 		return NewGoogleProducer()
+	case "kubernetes":
+		return NewKubernetesProducer()
+	case "file":
+		return NewFileProducer()
 	}
 	return nil, fmt.Errorf("Unknown producer '%s'", name)
 }
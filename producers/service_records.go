@@ -0,0 +1,70 @@
+package producers
+
+import (
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/everesio/buddy/pkg"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// service or protocol label of SRV name e.g. _postgres or _tcp
+	serviceLabel = regexp.MustCompile(`^_[a-zA-Z0-9]([-a-zA-Z0-9]{0,60}[a-zA-Z0-9])?$`)
+)
+
+// service is a SRV record declared by the instance
+type service struct {
+	name     string
+	port     int
+	priority int
+	weight   int
+}
+
+// parseService parses _service._proto:port:priority:weight e.g. _postgres._tcp:5432:10:5
+func parseService(value string) (*service, error) {
+	fields := strings.Split(value, ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf("Invalid service '%s': expected _service._proto:port:priority:weight", value)
+	}
+	labels := strings.Split(fields[0], ".")
+	if len(labels) != 2 || !serviceLabel.MatchString(labels[0]) || !serviceLabel.MatchString(labels[1]) {
+		return nil, fmt.Errorf("Invalid service '%s': name '%s' must be _service._proto", value, fields[0])
+	}
+	numbers := make([]int, 0, 3)
+	for _, field := range fields[1:] {
+		number, err := strconv.Atoi(field)
+		if err != nil || number < 0 || number > 65535 {
+			return nil, fmt.Errorf("Invalid service '%s': '%s' must be a number 0-65535", value, field)
+		}
+		numbers = append(numbers, number)
+	}
+	if numbers[0] == 0 {
+		return nil, fmt.Errorf("Invalid service '%s': port must not be 0", value)
+	}
+	return &service{name: fields[0], port: numbers[0], priority: numbers[1], weight: numbers[2]}, nil
+}
+
+// srvEndpoints returns SRV endpoints of comma separated services e.g. _postgres._tcp:5432:10:5 pointing at the hostname of endpoints
+func (gp *GoogleProducer) srvEndpoints(googleInstance *googleInstance, index int, keySRV string, endpoints []*pkg.Endpoint) []*pkg.Endpoint {
+	value, ok := gp.lookup(googleInstance, nicKeys(index, keySRV))
+	if !ok || len(endpoints) == 0 {
+		return nil
+	}
+	var result []*pkg.Endpoint
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		s, err := parseService(v)
+		if err != nil {
+			log.Warnf("Skip service of instance %s nic%d: %v", googleInstance.Name, index, err)
+			continue
+		}
+		result = append(result, &pkg.Endpoint{Hostname: s.name, DNSZone: endpoints[0].DNSZone, Type: pkg.RecordTypeSRV, Target: endpoints[0].Hostname,
+			Port: s.port, Priority: s.priority, Weight: s.weight, ComputeZone: googleInstance.ComputeZone, Project: googleInstance.Project})
+	}
+	return result
+}
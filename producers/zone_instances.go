@@ -0,0 +1,105 @@
+package producers
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	// compute zone label of aggregated list metrics
+	allComputeZones = "all"
+	// DefaultZoneConcurrency is the default number of compute zones listed in parallel
+	DefaultZoneConcurrency = 4
+)
+
+// zoneInstances are instances of a compute zone in the project
+type zoneInstances struct {
+	project   string
+	zone      string
+	instances []googleInstance
+}
+
+// listZoneInstances lists instances of each project zone with at most concurrency parallel calls of list.
+// Results are in the order of projects and zones, the first error is returned.
+func listZoneInstances(projects []string, zones []string, concurrency int, list func(project int, zone string) ([]googleInstance, error)) ([]zoneInstances, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultZoneConcurrency
+	}
+	result := make([]zoneInstances, len(projects)*len(zones))
+	errs := make([]error, len(result))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, project := range projects {
+		for j, zone := range zones {
+			index := i*len(zones) + j
+			result[index] = zoneInstances{project: project, zone: zone}
+			wg.Add(1)
+			go func(project int, zone string, index int) {
+				defer wg.Done()
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+				result[index].instances, errs[index] = list(project, zone)
+			}(i, zone, index)
+		}
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// instances lists instances of managed compute zones. In all zones mode, the aggregated list is used
+// and compute zones are updated with zones of the projects.
+func (gp *GoogleProducer) instances() ([]zoneInstances, error) {
+	if !gp.allZones {
+		projects := make([]string, 0, len(gp.computeEngineServices))
+		for _, computeEngineService := range gp.computeEngineServices {
+			projects = append(projects, computeEngineService.project)
+		}
+		return listZoneInstances(projects, gp.zones(), gp.zoneConcurrency, func(project int, zone string) ([]googleInstance, error) {
+			return gp.computeEngineServices[project].getInstances(zone)
+		})
+	}
+
+	result := make([]zoneInstances, 0, 16)
+	for _, computeEngineService := range gp.computeEngineServices {
+		instancesByZone, err := computeEngineService.getAllInstances()
+		if err != nil {
+			return nil, err
+		}
+		zones := make([]string, 0, len(instancesByZone))
+		for zone := range instancesByZone {
+			zones = append(zones, zone)
+		}
+		sort.Strings(zones)
+		for _, zone := range zones {
+			result = append(result, zoneInstances{project: computeEngineService.project, zone: zone, instances: instancesByZone[zone]})
+		}
+		gp.addComputeZones(zones)
+	}
+	return result, nil
+}
+
+// addComputeZones adds zones to managed compute zones. Zones are never removed, so records of removed instances are deleted.
+func (gp *GoogleProducer) addComputeZones(zones []string) {
+	gp.mu.Lock()
+	defer gp.mu.Unlock()
+	gp.computeZones = union(gp.computeZones, zones)
+}
+
+// union returns a new sorted slice with unique values of a and b
+func union(a []string, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, v := range append(append([]string{}, a...), b...) {
+		if _, ok := seen[v]; !ok {
+			seen[v] = struct{}{}
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,51 @@
+package producers
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestListZoneInstances(t *testing.T) {
+	a := assert.New(t)
+
+	var running, maxRunning int32
+	list := func(project int, zone string) ([]googleInstance, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return []googleInstance{{Name: zone, ComputeZone: zone}}, nil
+	}
+	result, err := listZoneInstances([]string{"project-a", "project-b"}, []string{"zone-a", "zone-b", "zone-c"}, 2, list)
+	a.NoError(err)
+	a.True(maxRunning <= 2, "concurrency %d", maxRunning)
+	a.Len(result, 6)
+	a.Equal("project-a", result[0].project)
+	a.Equal("zone-a", result[0].zone)
+	a.Equal("project-b", result[5].project)
+	a.Equal("zone-c", result[5].zone)
+	a.Equal("zone-c", result[5].instances[0].Name)
+
+	_, err = listZoneInstances([]string{"project-a"}, []string{"zone-a", "zone-b"}, 0, func(project int, zone string) ([]googleInstance, error) {
+		if zone == "zone-b" {
+			return nil, errors.New("quota exceeded")
+		}
+		return nil, nil
+	})
+	a.Error(err)
+}
+
+func TestAddComputeZones(t *testing.T) {
+	gp := &GoogleProducer{}
+	gp.addComputeZones([]string{"zone-b", "zone-a"})
+	gp.addComputeZones([]string{"zone-c", "zone-a"})
+	assert.Equal(t, []string{"zone-a", "zone-b", "zone-c"}, gp.ComputeZones())
+}